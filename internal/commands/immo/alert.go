@@ -0,0 +1,134 @@
+package immo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Severities of the alerts.
+const (
+	severityInfo    = "info"
+	severityWarning = "warning"
+	severityError   = "error"
+)
+
+var severities = []string{severityInfo, severityWarning, severityError}
+
+// Codes of the built-in alerts.
+const (
+	alertMonthlyCostMismatch     = "monthly_cost_mismatch"
+	alertPartialInsurance        = "partial_insurance"
+	alertContributionAboveLimit  = "contribution_above_threshold"
+	alertCityStatsNotFound       = "city_stats_not_found"
+	alertRentsAboveRegimeCeiling = "rents_above_regime_ceiling"
+	alertDebtRatioExceeded       = "debt_ratio_exceeded"
+	alertBridgeLoanTooShort      = "bridge_loan_too_short"
+	alertSaleDoesNotCoverBridge  = "sale_does_not_cover_bridge_loan"
+	alertRentalBan               = "rental_ban"
+	alertRentFrozen              = "rent_frozen"
+	alertPriceAboveFairValue     = "price_above_fair_value"
+	alertRuleFailed              = "alert_rule_failed"
+)
+
+// Alert is an alert raised by an evaluation.
+type Alert struct {
+	Code     string `yaml:"code" json:"code"`
+	Severity string `yaml:"severity" json:"severity"` // info, warning or error
+	Message  string `yaml:"message" json:"message"`
+}
+
+func newAlert(code, severity, format string, args ...any) Alert {
+	return Alert{Code: code, Severity: severity, Message: fmt.Sprintf(format, args...)}
+}
+
+// alertMessages returns the messages of the alerts.
+func alertMessages(alerts []Alert) []string {
+	messages := make([]string, len(alerts))
+	for i, a := range alerts {
+		messages[i] = a.Message
+	}
+	return messages
+}
+
+// AlertRule is an alert defined in the configuration, raised when its condition holds for a good
+// and the result of its evaluation.
+type AlertRule struct {
+	// Code identifies the alert, e.g. low_remaining_assets.
+	Code string `yaml:"code"`
+
+	// Severity is the severity of the alert: info, warning or error. Defaults to warning.
+	Severity string `yaml:"severity"`
+
+	// When is the condition of the alert, an expression over the fields of the good and of the
+	// result, e.g. good.energy_performance_rating in ["F", "G"] or result.remaining_assets < 30000.
+	// The fields are named as in the configuration and in the YAML output of evaluate.
+	When string `yaml:"when"`
+
+	// Message is the message of the alert. Paths between braces are replaced by their values,
+	// e.g. "Only {result.remaining_assets} left after the purchase of {good.name}".
+	Message string `yaml:"message"`
+}
+
+// messagePlaceholderRe matches the paths between braces of a message template.
+var messagePlaceholderRe = regexp.MustCompile(`\{([^{}]*)\}`)
+
+func (r AlertRule) severity() string {
+	if r.Severity != "" {
+		return r.Severity
+	}
+	return severityWarning
+}
+
+// check returns the alert of the rule if its condition holds in the environment. A rule which
+// cannot be evaluated raises an alert explaining why, rather than being silently ignored.
+func (r AlertRule) check(env map[string]any) (Alert, bool) {
+	condition, err := parseExpr(r.When)
+	if err != nil {
+		return newAlert(alertRuleFailed, severityError, "Alert rule %s is invalid: %v", r.Code, err), true
+	}
+	value, err := condition.eval(env)
+	if err != nil {
+		return newAlert(alertRuleFailed, severityError, "Alert rule %s failed: %v", r.Code, err), true
+	}
+	if !truthy(value) {
+		return Alert{}, false
+	}
+	message := fmt.Sprintf("Alert rule %s is triggered", r.Code)
+	if r.Message != "" {
+		message = messagePlaceholderRe.ReplaceAllStringFunc(r.Message, func(placeholder string) string {
+			value, err := lookupPath(env, strings.TrimSpace(placeholder[1:len(placeholder)-1]))
+			if err != nil {
+				return err.Error()
+			}
+			return formatValue(value)
+		})
+	}
+	return Alert{Code: r.Code, Severity: r.severity(), Message: message}, true
+}
+
+// ruleAlerts returns the alerts raised by the rules for a good and the result of its evaluation.
+func ruleAlerts(rules []AlertRule, good Property, result EvaluationResult) []Alert {
+	if len(rules) == 0 {
+		return nil
+	}
+	var (
+		alerts []Alert
+		env    = evaluationEnv(good, result)
+	)
+	for _, rule := range rules {
+		if alert, raised := rule.check(env); raised {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts
+}
+
+// messagePaths returns the paths between braces of a message template.
+func messagePaths(message string) []string {
+	var paths []string
+	for _, match := range messagePlaceholderRe.FindAllStringSubmatch(message, -1) {
+		paths = append(paths, strings.TrimSpace(match[1]))
+	}
+	return paths
+}
@@ -0,0 +1,167 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+var amortizeCmd = &cobra.Command{
+	Use:   "amortize [bank or plan]",
+	Short: "Print the amortization table of the estimated mortgages and financing plans.",
+	RunE:  runAmortize,
+}
+
+var (
+	amortizeYearly bool
+	amortizeLines  bool
+)
+
+func init() {
+	amortizeCmd.Flags().BoolVar(&amortizeYearly, "yearly", false, "Aggregate the amortization table by year")
+	amortizeCmd.Flags().BoolVar(&amortizeLines, "lines", false, "Print the amortization table of each loan line of the financing plans")
+}
+
+func runAmortize(cmd *cobra.Command, args []string) error {
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+
+	var plans []FinancingPlan
+	for _, plan := range cfg.financingPlans() {
+		if len(args) == 0 || args[0] == plan.Name {
+			plans = append(plans, plan)
+		}
+	}
+	if len(plans) == 0 {
+		if len(args) > 0 {
+			return fmt.Errorf("mortgage %q not found", args[0])
+		}
+		return errors.New("no mortgage found in the configuration")
+	}
+
+	for i, plan := range plans {
+		if len(plan.Loans) == 1 {
+			printMortgageAmortization(i+1, plan.Loans[0])
+		} else {
+			printPlanAmortization(i+1, plan)
+		}
+	}
+	return nil
+}
+
+func printMortgageAmortization(index int, mortgage Mortgage) {
+	fmt.Printf("%d. Mortgage %s %.0fK at %.2f%% over %d years\n", index, mortgage.Bank, math.Round(mortgage.Amount/1000), mortgage.InterestRate*100, mortgage.Years)
+	fmt.Println("==========")
+	if !mortgage.HasTerms() {
+		fmt.Println("Interest rate or duration is missing, cannot compute the amortization.")
+		fmt.Println()
+		return
+	}
+	fmt.Printf("Monthly payment: %.2f (+ %.2f insurance)\n", mortgage.ComputedMonthlyCost(), mortgage.MonthlyInsurance())
+	if mortgage.DeferredMonths > 0 {
+		fmt.Printf("Deferral:        %d months (%s)\n", mortgage.DeferredMonths, mortgage.deferralType())
+	}
+	fmt.Printf("Total interest:  %.0f\n", mortgage.TotalInterest())
+	fmt.Printf("Total insurance: %.0f\n", mortgage.TotalInsurance())
+	if mortgage.MonthlyCostMismatch() {
+		fmt.Printf("Warning: typed monthly cost %.2f differs from the computed one\n", mortgage.MonthlyCost)
+	}
+	fmt.Println()
+	printSchedule(mortgage.Schedule())
+}
+
+func printPlanAmortization(index int, plan FinancingPlan) {
+	smoothed := ""
+	if plan.Smoothed {
+		smoothed = ", smoothed"
+	}
+	fmt.Printf("%d. Financing plan %s %.0fK (%d loans%s)\n", index, plan.Name, math.Round(plan.Amount()/1000), len(plan.Loans), smoothed)
+	fmt.Println("==========")
+	if !plan.HasTerms() {
+		fmt.Println("Interest rate or duration is missing, cannot compute the amortization.")
+		fmt.Println()
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Bank\tKind\tAmount\tRate\tYears\tDeferral\tPayment\tInterest\t")
+	for _, line := range plan.lines() {
+		fmt.Fprintf(w, "%s\t%s\t%.0f\t%.2f%%\t%d\t%d\t%.0f\t%.0f\t\n",
+			line.Bank,
+			line.Kind,
+			line.Amount,
+			line.InterestRate*100,
+			line.Years,
+			line.DeferredMonths,
+			line.MonthlyPayment,
+			line.TotalInterest,
+		)
+	}
+	w.Flush()
+	fmt.Println()
+	fmt.Printf("Highest monthly payment: %.2f (+ %.2f insurance)\n", plan.MonthlyPayment(), plan.Insurance())
+	fmt.Printf("Total interest:          %.0f\n", plan.TotalInterest())
+	fmt.Printf("Total insurance:         %.0f\n", plan.TotalInsurance())
+	fmt.Println()
+	printSchedule(plan.Schedule())
+
+	if amortizeLines {
+		for i, rows := range plan.LineSchedules() {
+			fmt.Printf("%d.%d. Loan %s %.0fK\n", index, i+1, plan.Loans[i].Bank, math.Round(plan.Loans[i].Amount/1000))
+			fmt.Println("----------")
+			printSchedule(rows)
+		}
+	}
+}
+
+func printSchedule(rows []AmortizationRow) {
+	if amortizeYearly {
+		rows = aggregateByYear(rows)
+	}
+	printAmortization(rows, amortizeYearly)
+	fmt.Println()
+}
+
+// aggregateByYear merges monthly rows into yearly rows. The month of a yearly row is the year
+// number, and the remaining capital is the one at the end of the year.
+func aggregateByYear(rows []AmortizationRow) []AmortizationRow {
+	var years []AmortizationRow
+	for _, row := range rows {
+		year := (row.Month-1)/12 + 1
+		if len(years) < year {
+			years = append(years, AmortizationRow{Month: year})
+		}
+		y := &years[year-1]
+		y.Payment += row.Payment
+		y.Principal += row.Principal
+		y.Interest += row.Interest
+		y.Insurance += row.Insurance
+		y.RemainingCapital = row.RemainingCapital
+	}
+	return years
+}
+
+func printAmortization(rows []AmortizationRow, yearly bool) {
+	period := "Month"
+	if yearly {
+		period = "Year"
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(w, "%s\tPayment\tPrincipal\tInterest\tInsurance\tRemaining\t\n", period)
+	for _, row := range rows {
+		fmt.Fprintf(w, "%d\t%.2f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			row.Month,
+			row.Payment,
+			row.Principal,
+			row.Interest,
+			row.Insurance,
+			row.RemainingCapital,
+		)
+	}
+	w.Flush()
+}
@@ -0,0 +1,146 @@
+package immo
+
+import "math"
+
+// Repayment modes of a bridge loan.
+const (
+	// bridgeInterestOnly means that the interest is paid every month, and the capital is repaid
+	// at the sale.
+	bridgeInterestOnly = "interest_only"
+	// bridgeDeferred means that nothing is paid before the sale: the interest is capitalized and
+	// repaid with the capital (relais différé total).
+	bridgeDeferred = "deferred"
+)
+
+// defaultBridgeValueRate is the share of the estimated sale price usually lent by banks for a
+// bridge loan.
+const defaultBridgeValueRate = 0.7
+
+// bridgeSaleDelays are the delays of the sale, in months, compared in the evaluation.
+var bridgeSaleDelays = []int{0, 6, 12}
+
+// BridgeLoan is a bridge loan (prêt relais) backed by the value of the current property, to buy
+// the new property before the current one is sold.
+type BridgeLoan struct {
+	// ValueRate is the share of the estimated sale price lent by the bank, usually between 60% and
+	// 80%. The remaining capital of the current loan is deducted from it. Default to 70%.
+	ValueRate float64 `yaml:"value_rate"`
+
+	// InterestRate is the annual rate of the bridge loan, e.g. 0.045.
+	InterestRate float64 `yaml:"interest_rate"`
+
+	// Months is the maximum duration of the bridge loan, usually 12 or 24 months.
+	Months int `yaml:"months"`
+
+	// Repayment is the repayment mode: interest_only (default) or deferred.
+	Repayment string `yaml:"repayment"`
+
+	// ExpectedSaleMonths is the number of months between the purchase and the expected sale of the
+	// current property.
+	ExpectedSaleMonths int `yaml:"expected_sale_months"`
+
+	// Insurance is the monthly insurance of the bridge loan.
+	Insurance float64 `yaml:"insurance"`
+}
+
+func (b BridgeLoan) valueRate() float64 {
+	if b.ValueRate > 0 {
+		return b.ValueRate
+	}
+	return defaultBridgeValueRate
+}
+
+func (b BridgeLoan) repayment() string {
+	if b.Repayment == bridgeDeferred {
+		return bridgeDeferred
+	}
+	return bridgeInterestOnly
+}
+
+// amount returns the amount lent: a share of the estimated sale price, minus the remaining capital
+// of the current loan which is repaid at the sale.
+func (b BridgeLoan) amount(cp CurrentPropertyContext) float64 {
+	return math.Max(cp.EstimatedSalePrice*b.valueRate()-cp.RemainingLoanCapital, 0)
+}
+
+// monthlyPayment returns the monthly payment of the bridge loan before the sale, without insurance.
+func (b BridgeLoan) monthlyPayment(amount float64) float64 {
+	if b.repayment() == bridgeDeferred {
+		return 0
+	}
+	return amount * b.InterestRate / 12
+}
+
+// interest returns the interest of the bridge loan when the sale happens after the given months.
+func (b BridgeLoan) interest(amount float64, months int) float64 {
+	if b.repayment() == bridgeDeferred {
+		return amount * (math.Pow(1+b.InterestRate/12, float64(months)) - 1)
+	}
+	return b.monthlyPayment(amount) * float64(months)
+}
+
+// computeBridgeLoan returns the financing of the purchase with a bridge loan, and the alerts
+// raised when the sale may happen after the end of the bridge loan. It returns nil when there is
+// no bridge loan or no estimated sale price.
+//
+// The monthly loan payments are the payments of the new financing and of the current loan,
+// insurance included. Until the sale, the family pays both of them, plus the bridge loan, without
+// the rents of the current property which is put on sale.
+func computeBridgeLoan(ctx EvaluationContext, purchaseCost, monthlyLoanPayments float64) (*BridgeLoanResult, []Alert) {
+	cp := ctx.CurrentProperty
+	if cp.BridgeLoan == nil || cp.EstimatedSalePrice == 0 {
+		return nil, nil
+	}
+	var (
+		bridge     = *cp.BridgeLoan
+		amount     = bridge.amount(cp)
+		sale       = computeSale(cp)
+		bridgeCost = bridge.monthlyPayment(amount) + bridge.Insurance
+		burden     = monthlyLoanPayments + bridgeCost
+		alerts     []Alert
+		// until the sale, the current property is empty: its loan, charges and taxes are paid
+		// without any rent
+		holdingCost = cp.MonthlyMortgage + cp.MonthlyCharges + cp.AnnualPropertyTax/12
+	)
+	result := &BridgeLoanResult{
+		Amount:              math.Round(amount),
+		Repayment:           bridge.repayment(),
+		Contribution:        math.Round(purchaseCost - ctx.Financing.Amount() - amount),
+		MonthlyBridgeCost:   math.Round(bridgeCost),
+		MonthlyDoubleBurden: math.Round(burden),
+	}
+	if ctx.Family.MonthlyNetIncome() > 0 {
+		result.DebtRatio = computeDebtRatio(ctx.Family, 0, burden).rounded().Ratio
+	}
+
+	for _, delay := range bridgeSaleDelays {
+		var (
+			months   = bridge.ExpectedSaleMonths + delay
+			interest = bridge.interest(amount, months)
+			// the interest paid monthly is not repaid at the sale, only the capitalized one
+			repayment   = amount + interest - bridge.monthlyPayment(amount)*float64(months)
+			capitalized = repayment - amount
+		)
+		scenario := BridgeSaleScenario{
+			SaleDelayMonths:      delay,
+			SaleMonth:            months,
+			BridgeInterest:       math.Round(interest),
+			CarryingCost:         math.Round((bridgeCost+holdingCost)*float64(months) + capitalized),
+			RepaymentAtSale:      math.Round(repayment),
+			NetProceedsAfterLoan: math.Round(sale.NetProceeds - repayment),
+			ExceedsBridgeTerm:    months > bridge.Months,
+		}
+		result.Scenarios = append(result.Scenarios, scenario)
+		if scenario.ExceedsBridgeTerm && len(alerts) == 0 {
+			if delay == 0 {
+				alerts = append(alerts, newAlert(alertBridgeLoanTooShort, severityError, "Bridge loan ends before the expected sale (%d > %d months)", months, bridge.Months))
+			} else {
+				alerts = append(alerts, newAlert(alertBridgeLoanTooShort, severityWarning, "Bridge loan ends before the sale if it slips by %d months (%d > %d months)", delay, months, bridge.Months))
+			}
+		}
+	}
+	if result.Scenarios[0].NetProceedsAfterLoan < 0 {
+		alerts = append(alerts, newAlert(alertSaleDoesNotCoverBridge, severityError, "Sale does not cover the bridge loan (%.0f missing)", -result.Scenarios[0].NetProceedsAfterLoan))
+	}
+	return result, alerts
+}
@@ -0,0 +1,129 @@
+package immo
+
+import (
+	"reflect"
+	"testing"
+)
+
+// bridgeContext is a purchase of 500000 financed by 300000 of loans, before the sale of a current
+// property estimated at 300000, with 100000 remaining due: 70% of the price minus the remaining
+// capital gives a bridge loan of 110000, and the sale nets 187000 after the agency fees and the
+// early repayment penalty.
+func bridgeContext(bridge BridgeLoan) EvaluationContext {
+	return EvaluationContext{
+		Family: FamilyContext{Borrowers: []Borrower{{Name: "A", MonthlyNetIncome: 6000}}},
+		CurrentProperty: CurrentPropertyContext{
+			MonthlyMortgage:      800,
+			MonthlyCharges:       100,
+			AnnualPropertyTax:    1200,
+			EstimatedSalePrice:   300000,
+			SaleAgencyFeesRate:   0.04,
+			RemainingLoanCapital: 100000,
+			LoanInterestRate:     0.02,
+			BridgeLoan:           &bridge,
+		},
+		Financing: singleLoanPlan(Mortgage{Amount: 300000}),
+	}
+}
+
+func TestComputeBridgeLoan(t *testing.T) {
+	tests := []struct {
+		name   string
+		bridge BridgeLoan
+		want   *BridgeLoanResult
+		alerts []Alert
+	}{
+		{
+			// 440 of interest a month, paid until the sale: the carrying cost adds the insurance and
+			// the 1000 a month of the empty current property
+			name:   "interest only",
+			bridge: BridgeLoan{InterestRate: 0.048, Months: 12, ExpectedSaleMonths: 6, Insurance: 10},
+			want: &BridgeLoanResult{
+				Amount:              110000,
+				Repayment:           bridgeInterestOnly,
+				Contribution:        90000,
+				MonthlyBridgeCost:   450,
+				MonthlyDoubleBurden: 2850,
+				DebtRatio:           0.475,
+				Scenarios: []BridgeSaleScenario{
+					{SaleDelayMonths: 0, SaleMonth: 6, BridgeInterest: 2640, CarryingCost: 8700, RepaymentAtSale: 110000, NetProceedsAfterLoan: 77000},
+					{SaleDelayMonths: 6, SaleMonth: 12, BridgeInterest: 5280, CarryingCost: 17400, RepaymentAtSale: 110000, NetProceedsAfterLoan: 77000},
+					{SaleDelayMonths: 12, SaleMonth: 18, BridgeInterest: 7920, CarryingCost: 26100, RepaymentAtSale: 110000, NetProceedsAfterLoan: 77000, ExceedsBridgeTerm: true},
+				},
+			},
+			alerts: []Alert{newAlert(alertBridgeLoanTooShort, severityWarning, "Bridge loan ends before the sale if it slips by 12 months (18 > 12 months)")},
+		},
+		{
+			// nothing is paid before the sale: the interest is capitalized and repaid at the sale
+			name:   "deferred",
+			bridge: BridgeLoan{InterestRate: 0.048, Months: 12, Repayment: bridgeDeferred, ExpectedSaleMonths: 6, Insurance: 10},
+			want: &BridgeLoanResult{
+				Amount:              110000,
+				Repayment:           bridgeDeferred,
+				Contribution:        90000,
+				MonthlyBridgeCost:   10,
+				MonthlyDoubleBurden: 2410,
+				DebtRatio:           0.402,
+				Scenarios: []BridgeSaleScenario{
+					{SaleDelayMonths: 0, SaleMonth: 6, BridgeInterest: 2667, CarryingCost: 8727, RepaymentAtSale: 112667, NetProceedsAfterLoan: 74333},
+					{SaleDelayMonths: 6, SaleMonth: 12, BridgeInterest: 5398, CarryingCost: 17518, RepaymentAtSale: 115398, NetProceedsAfterLoan: 71602},
+					{SaleDelayMonths: 12, SaleMonth: 18, BridgeInterest: 8195, CarryingCost: 26375, RepaymentAtSale: 118195, NetProceedsAfterLoan: 68805, ExceedsBridgeTerm: true},
+				},
+			},
+			alerts: []Alert{newAlert(alertBridgeLoanTooShort, severityWarning, "Bridge loan ends before the sale if it slips by 12 months (18 > 12 months)")},
+		},
+	}
+	for _, tt := range tests {
+		got, alerts := computeBridgeLoan(bridgeContext(tt.bridge), 500000, 2400)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: computeBridgeLoan() = %+v, want %+v", tt.name, got, tt.want)
+		}
+		if !reflect.DeepEqual(alerts, tt.alerts) {
+			t.Errorf("%s: alerts = %+v, want %+v", tt.name, alerts, tt.alerts)
+		}
+	}
+}
+
+func TestComputeBridgeLoanAlerts(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    EvaluationContext
+		alerts []Alert
+	}{
+		{
+			name:   "sale expected after the bridge loan",
+			ctx:    bridgeContext(BridgeLoan{InterestRate: 0.048, Months: 12, ExpectedSaleMonths: 15}),
+			alerts: []Alert{newAlert(alertBridgeLoanTooShort, severityError, "Bridge loan ends before the expected sale (15 > 12 months)")},
+		},
+		{
+			name:   "sale within the bridge loan, even with a delay",
+			ctx:    bridgeContext(BridgeLoan{InterestRate: 0.048, Months: 24, ExpectedSaleMonths: 6}),
+			alerts: nil,
+		},
+		{
+			// the agency fees of 30% leave 109000 of net proceeds for a bridge loan of 110000
+			name: "sale not covering the bridge loan",
+			ctx: func() EvaluationContext {
+				ctx := bridgeContext(BridgeLoan{InterestRate: 0.048, Months: 24, ExpectedSaleMonths: 6})
+				ctx.CurrentProperty.SaleAgencyFeesRate = 0.3
+				return ctx
+			}(),
+			alerts: []Alert{newAlert(alertSaleDoesNotCoverBridge, severityError, "Sale does not cover the bridge loan (1000 missing)")},
+		},
+	}
+	for _, tt := range tests {
+		if _, alerts := computeBridgeLoan(tt.ctx, 500000, 2400); !reflect.DeepEqual(alerts, tt.alerts) {
+			t.Errorf("%s: alerts = %+v, want %+v", tt.name, alerts, tt.alerts)
+		}
+	}
+
+	ctx := bridgeContext(BridgeLoan{InterestRate: 0.048, Months: 12})
+	ctx.CurrentProperty.EstimatedSalePrice = 0
+	if got, _ := computeBridgeLoan(ctx, 500000, 2400); got != nil {
+		t.Errorf("computeBridgeLoan() without sale price = %+v, want nil", got)
+	}
+	ctx.CurrentProperty.BridgeLoan = nil
+	if got, _ := computeBridgeLoan(ctx, 500000, 2400); got != nil {
+		t.Errorf("computeBridgeLoan() without bridge loan = %+v, want nil", got)
+	}
+}
@@ -0,0 +1,225 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// bisectionRounds is the maximum number of rounds used to solve a price by bisection.
+const bisectionRounds = 100
+
+var capacityCmd = &cobra.Command{
+	Use:   "capacity",
+	Short: "Compute the maximum loan and property price that the family can afford.",
+	RunE:  runCapacity,
+}
+
+var (
+	capacityOutput         string
+	capacityRate           float64
+	capacityYears          int
+	capacityInsuranceRate  float64
+	capacityMinResteAVivre float64
+	capacityZipCode        string
+	capacityNewBuild       bool
+	capacityRenovationCost float64
+)
+
+func init() {
+	capacityCmd.Flags().StringVarP(&capacityOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	capacityCmd.Flags().Float64Var(&capacityRate, "rate", 0.035, "Annual interest rate of the mortgage, e.g. 0.035 for 3.5%")
+	capacityCmd.Flags().IntVar(&capacityYears, "years", 25, "Duration of the mortgage in years")
+	capacityCmd.Flags().Float64Var(&capacityInsuranceRate, "insurance-rate", 0.003, "Annual rate of the borrower insurance, applied to the initial capital")
+	capacityCmd.Flags().Float64Var(&capacityMinResteAVivre, "min-reste-a-vivre", 0, "Minimum monthly income left after loan payments (default: family.minimum_reste_a_vivre)")
+	capacityCmd.Flags().StringVar(&capacityZipCode, "zip-code", "", "Zip code of the property, used for the transfer taxes")
+	capacityCmd.Flags().BoolVar(&capacityNewBuild, "new-build", false, "Whether the property is a new build (neuf or VEFA)")
+	capacityCmd.Flags().Float64Var(&capacityRenovationCost, "renovation-cost", 0, "Renovation cost to finance on top of the price")
+}
+
+func runCapacity(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(capacityOutput)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Family.MonthlyNetIncome() == 0 {
+		return errors.New("family.borrowers must declare their incomes to compute the capacity")
+	}
+	if cmd.Flags().Changed("min-reste-a-vivre") {
+		cfg.Family.MinimumResteAVivre = capacityMinResteAVivre
+	}
+
+	result, err := computeCapacity(cfg, CapacityInput{
+		InterestRate:   capacityRate,
+		Years:          capacityYears,
+		InsuranceRate:  capacityInsuranceRate,
+		ZipCode:        capacityZipCode,
+		NewBuild:       capacityNewBuild,
+		RenovationCost: capacityRenovationCost,
+	})
+	if err != nil {
+		return err
+	}
+	if format.isDocument() {
+		return writeDocument(os.Stdout, format, result)
+	}
+	return writeTable(os.Stdout, format, result.table())
+}
+
+// CapacityInput is the hypothesis used to compute the borrowing capacity.
+type CapacityInput struct {
+	InterestRate   float64
+	Years          int
+	InsuranceRate  float64 // annual, on the initial capital
+	ZipCode        string
+	NewBuild       bool
+	RenovationCost float64
+}
+
+// CapacityResult is the borrowing capacity of the family.
+type CapacityResult struct {
+	MonthlyIncome       float64 `yaml:"monthly_income" json:"monthly_income"` // net incomes + 70% of the rents
+	ExistingLoanPayment float64 `yaml:"existing_loan_payment" json:"existing_loan_payment"`
+	MaxMonthlyPayment   float64 `yaml:"max_monthly_payment" json:"max_monthly_payment"` // insurance included
+	LimitedBy           string  `yaml:"limited_by" json:"limited_by"`
+	MaxLoan             float64 `yaml:"max_loan" json:"max_loan"`
+	Contribution        float64 `yaml:"contribution" json:"contribution"`
+	MaxPurchaseBudget   float64 `yaml:"max_purchase_budget" json:"max_purchase_budget"` // loan + contribution
+	MaxPrice            float64 `yaml:"max_price" json:"max_price"`
+	AcquisitionFees     float64 `yaml:"acquisition_fees" json:"acquisition_fees"`
+	RenovationCost      float64 `yaml:"renovation_cost" json:"renovation_cost"`
+	MonthlyMortgageCost float64 `yaml:"monthly_mortgage_cost" json:"monthly_mortgage_cost"`
+	DebtRatio           float64 `yaml:"debt_ratio" json:"debt_ratio"`
+	ResteAVivre         float64 `yaml:"reste_a_vivre" json:"reste_a_vivre"`
+}
+
+// computeCapacity solves the maximum loan and the maximum price of a property, so that the debt
+// ratio stays under the limit, the reste-à-vivre stays above the minimum, and the contribution
+// stays under the threshold. The result is checked against evaluate(), so that both commands
+// always agree: it is an error when the evaluation of the maximum price breaks a limit.
+func computeCapacity(cfg ImmoConfig, input CapacityInput) (CapacityResult, error) {
+	var (
+		family   = cfg.Family
+		cp       = cfg.CurrentProperty
+		income   = family.MonthlyNetIncome() + cp.MonthlyIncome*rentalIncomeWeight
+		existing = cp.MonthlyMortgage + family.OtherMonthlyLoanPayments
+
+		byDebtRatio   = family.debtRatioLimit()*income - existing
+		byResteAVivre = income - existing - family.MinimumResteAVivre
+		maxPayment    = math.Max(math.Min(byDebtRatio, byResteAVivre), 0)
+		limitedBy     = "debt_ratio"
+	)
+	if byResteAVivre < byDebtRatio {
+		limitedBy = "reste_a_vivre"
+	}
+
+	// monthly payment, insurance included, for each euro borrowed
+	unit := Mortgage{Amount: 1, InterestRate: input.InterestRate, Years: input.Years}
+	paymentPerEuro := unit.ComputedMonthlyCost() + input.InsuranceRate/12
+	var maxLoan float64
+	if paymentPerEuro > 0 {
+		maxLoan = math.Floor(maxPayment / paymentPerEuro)
+	}
+
+	contribution := math.Max(family.TotalAssets, 0)
+	if family.ContributionThreshold > 0 {
+		contribution = math.Min(contribution, family.ContributionThreshold)
+	}
+	budget := maxLoan + contribution
+
+	good := Property{
+		Name:           "capacity",
+		ZipCode:        input.ZipCode,
+		NewBuild:       input.NewBuild,
+		RenovationCost: input.RenovationCost,
+	}
+	good.Price = maxPriceForBudget(good, cfg.Fees, budget)
+
+	mortgage := Mortgage{
+		Bank:         "capacity",
+		Amount:       maxLoan,
+		InterestRate: input.InterestRate,
+		Years:        input.Years,
+		Insurance:    maxLoan * input.InsuranceRate / 12,
+	}
+	// the good is synthetic, without surface nor characteristics: the performance against the city
+	// stats, the fair value and the alert rules of the user would run against fake data
+	ctx := newEvaluationContext(cfg, singleLoanPlan(mortgage))
+	ctx.CityStats = nil
+	ctx.FairValues = nil
+	ctx.AlertRules = nil
+	evaluation := evaluate(ctx, good)
+	if d := evaluation.DebtRatio; d != nil && d.Exceeded() {
+		return CapacityResult{}, fmt.Errorf("capacity disagrees with evaluate: debt ratio %.1f%% above the limit %.0f%%", d.Ratio*100, d.Limit*100)
+	}
+	if c := evaluation.NewPropertyPurchaseCost.Contribution; c > math.Round(contribution) {
+		return CapacityResult{}, fmt.Errorf("capacity disagrees with evaluate: contribution %.0f above %.0f", c, contribution)
+	}
+
+	result := CapacityResult{
+		MonthlyIncome:       math.Round(income),
+		ExistingLoanPayment: math.Round(existing),
+		MaxMonthlyPayment:   math.Round(maxPayment),
+		LimitedBy:           limitedBy,
+		MaxLoan:             maxLoan,
+		Contribution:        evaluation.NewPropertyPurchaseCost.Contribution,
+		MaxPurchaseBudget:   math.Round(budget),
+		MaxPrice:            math.Floor(good.Price),
+		AcquisitionFees:     evaluation.NewPropertyPurchaseCost.AcquisitionFees.Total,
+		RenovationCost:      input.RenovationCost,
+		MonthlyMortgageCost: evaluation.NewPropertyOperationalCost.MonthlyMortgageCost,
+		ResteAVivre:         math.Round(income - existing - mortgage.MonthlyPayment() - mortgage.MonthlyInsurance()),
+	}
+	if evaluation.DebtRatio != nil {
+		result.DebtRatio = evaluation.DebtRatio.Ratio
+	}
+	return result, nil
+}
+
+// maxPriceForBudget returns the highest price of the good such that the price, the acquisition
+// fees and the other purchase costs fit in the budget. The fees are not linear in the price, so
+// the price is solved by bisection.
+func maxPriceForBudget(good Property, fees FeesConfig, budget float64) float64 {
+	cost := func(price float64) float64 {
+		good.Price = price
+		return totalPurchaseCost(good, computeAcquisitionFees(good, fees))
+	}
+	if cost(0) > budget {
+		return 0
+	}
+	lo, hi := 0.0, budget
+	for i := 0; i < bisectionRounds && hi-lo > 1; i++ {
+		mid := (lo + hi) / 2
+		if cost(mid) > budget {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	return lo
+}
+
+func (r CapacityResult) table() table {
+	t := table{headers: []string{"metric", "value"}}
+	t.append("monthly_income", formatAmount(r.MonthlyIncome))
+	t.append("existing_loan_payment", formatAmount(r.ExistingLoanPayment))
+	t.append("max_monthly_payment", formatAmount(r.MaxMonthlyPayment))
+	t.append("limited_by", r.LimitedBy)
+	t.append("max_loan", formatAmount(r.MaxLoan))
+	t.append("contribution", formatAmount(r.Contribution))
+	t.append("max_purchase_budget", formatAmount(r.MaxPurchaseBudget))
+	t.append("max_price", formatAmount(r.MaxPrice))
+	t.append("acquisition_fees", formatAmount(r.AcquisitionFees))
+	t.append("renovation_cost", formatAmount(r.RenovationCost))
+	t.append("monthly_mortgage_cost", formatAmount(r.MonthlyMortgageCost))
+	t.append("debt_ratio", fmt.Sprintf("%.1f%%", r.DebtRatio*100))
+	t.append("reste_a_vivre", formatAmount(r.ResteAVivre))
+	return t
+}
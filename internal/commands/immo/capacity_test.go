@@ -0,0 +1,45 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeCapacity(t *testing.T) {
+	cfg := ImmoConfig{
+		Family: FamilyContext{
+			Borrowers:             []Borrower{{Name: "A", MonthlyNetIncome: 4000}, {Name: "B", MonthlyNetIncome: 2000}},
+			TotalAssets:           100000,
+			ContributionThreshold: 60000,
+		},
+	}
+	input := CapacityInput{InterestRate: 0.035, Years: 25, InsuranceRate: 0.003, ZipCode: "92160"}
+
+	result, err := computeCapacity(cfg, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 35% of 6000, 1 euro over 25 years at 3.5% costing 0.0050062 a month plus 0.00025 of insurance
+	if result.MaxMonthlyPayment != 2100 || result.LimitedBy != "debt_ratio" {
+		t.Errorf("max monthly payment = %.0f limited by %s, want 2100 limited by debt_ratio", result.MaxMonthlyPayment, result.LimitedBy)
+	}
+	if result.MaxLoan != 399525 {
+		t.Errorf("max loan = %.0f, want 399525", result.MaxLoan)
+	}
+	// the price and its fees use the whole budget, the contribution being capped by the threshold
+	if result.MaxPurchaseBudget != 459525 || math.Abs(result.Contribution-60000) > 1 {
+		t.Errorf("budget = %.0f, contribution = %.0f, want 459525 and 60000", result.MaxPurchaseBudget, result.Contribution)
+	}
+	if got := result.MaxPrice + result.AcquisitionFees; math.Abs(got-result.MaxPurchaseBudget) > 2 {
+		t.Errorf("price + fees = %.0f, want the budget %.0f", got, result.MaxPurchaseBudget)
+	}
+	if result.DebtRatio > 0.35 {
+		t.Errorf("debt ratio = %.3f, want at most 0.35", result.DebtRatio)
+	}
+
+	// the reste-à-vivre limits the payment once it leaves less than 35% of the incomes
+	cfg.Family.MinimumResteAVivre = 4500
+	if result, err := computeCapacity(cfg, input); err != nil || result.MaxMonthlyPayment != 1500 || result.LimitedBy != "reste_a_vivre" {
+		t.Errorf("with a reste-à-vivre of 4500: %.0f limited by %s, %v, want 1500 limited by reste_a_vivre", result.MaxMonthlyPayment, result.LimitedBy, err)
+	}
+}
@@ -0,0 +1,94 @@
+package immo
+
+import "fmt"
+
+// rentalBanYears are the years from which homes with a given DPE letter cannot be rented anymore,
+// as they are no longer considered decent housing (Loi Climat et Résilience).
+var rentalBanYears = map[string]int{
+	"G": 2025,
+	"F": 2028,
+	"E": 2034,
+}
+
+// rentFrozenRatings are the DPE letters whose rents cannot be increased since August 2022.
+var rentFrozenRatings = map[string]bool{
+	"F": true,
+	"G": true,
+}
+
+// Rental scenarios checked against the Loi Climat et Résilience.
+const (
+	rentalScenarioCurrentProperty = "current_property"
+	rentalScenarioInvestment      = "investment"
+)
+
+// rentalRestriction returns the restrictions applying to the rental of a home with the given DPE
+// letter, once the planned renovation is done. It returns nil when the letter is unknown.
+func rentalRestriction(scenario, rating, ratingAfterRenovation string) *RentalRestriction {
+	if rating == "" {
+		return nil
+	}
+	r := &RentalRestriction{Scenario: scenario, Rating: rating}
+	if ratingAfterRenovation != "" {
+		r.RatingAfterRenovation = ratingAfterRenovation
+		rating = ratingAfterRenovation
+	}
+	r.BanYear = rentalBanYears[rating]
+	r.RentFrozen = rentFrozenRatings[rating]
+	return r
+}
+
+// alerts returns the alerts raised by the restriction, for the rental described by the subject.
+func (r RentalRestriction) alerts(subject string) []Alert {
+	var (
+		alerts []Alert
+		rating = r.effectiveRating()
+	)
+	if r.BanYear > 0 {
+		alerts = append(alerts, newAlert(alertRentalBan, severityError, "Renting %s is banned from %d (DPE %s)", subject, r.BanYear, rating))
+	}
+	if r.RentFrozen {
+		alerts = append(alerts, newAlert(alertRentFrozen, severityWarning, "Rents of %s are frozen (DPE %s)", subject, rating))
+	}
+	return alerts
+}
+
+func (r RentalRestriction) effectiveRating() string {
+	if r.RatingAfterRenovation != "" {
+		return r.RatingAfterRenovation
+	}
+	return r.Rating
+}
+
+// rentalRestrictions returns the restrictions of the rental scenarios of an evaluation: renting
+// the current property when the good replaces it, or renting the good when it is evaluated as an
+// investment.
+func rentalRestrictions(cp CurrentPropertyContext, good Property) ([]RentalRestriction, []Alert) {
+	var (
+		restrictions []RentalRestriction
+		alerts       []Alert
+	)
+	if cp.MonthlyIncome > 0 && good.Investment == nil {
+		if r := rentalRestriction(rentalScenarioCurrentProperty, cp.EnergyPerformanceRating, cp.EnergyPerformanceRatingAfterRenovation); r != nil {
+			restrictions = append(restrictions, *r)
+			alerts = append(alerts, r.alerts("the current property")...)
+		}
+	}
+	if good.Investment != nil {
+		if r := rentalRestriction(rentalScenarioInvestment, good.EnergyPerformanceRating, good.EnergyPerformanceRatingAfterRenovation); r != nil {
+			restrictions = append(restrictions, *r)
+			alerts = append(alerts, r.alerts(fmt.Sprintf("%q", good.Name))...)
+		}
+	}
+	return restrictions, alerts
+}
+
+// rentalRestrictionOf returns the restriction of a rental scenario of the result, if any.
+func (r EvaluationResult) rentalRestrictionOf(scenario string) *RentalRestriction {
+	for i, restriction := range r.RentalRestrictions {
+		if restriction.Scenario == scenario {
+			return &r.RentalRestrictions[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package immo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRentalRestriction(t *testing.T) {
+	tests := []struct {
+		name                  string
+		rating                string
+		ratingAfterRenovation string
+		want                  *RentalRestriction
+	}{
+		{name: "unknown rating", want: nil},
+		{
+			name:   "G is banned and frozen",
+			rating: "G",
+			want:   &RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "G", BanYear: 2025, RentFrozen: true},
+		},
+		{
+			name:   "F is banned and frozen",
+			rating: "F",
+			want:   &RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "F", BanYear: 2028, RentFrozen: true},
+		},
+		{
+			name:   "E is banned but not frozen",
+			rating: "E",
+			want:   &RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "E", BanYear: 2034},
+		},
+		{
+			name:   "D is free",
+			rating: "D",
+			want:   &RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "D"},
+		},
+		{
+			// the renovation lifts the freeze, but not the ban of E
+			name:                  "renovated from G to E",
+			rating:                "G",
+			ratingAfterRenovation: "E",
+			want:                  &RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "G", RatingAfterRenovation: "E", BanYear: 2034},
+		},
+	}
+	for _, tt := range tests {
+		got := rentalRestriction(rentalScenarioCurrentProperty, tt.rating, tt.ratingAfterRenovation)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: rentalRestriction() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRentalRestrictions(t *testing.T) {
+	tests := []struct {
+		name         string
+		cp           CurrentPropertyContext
+		good         Property
+		restrictions []RentalRestriction
+		alerts       []Alert
+	}{
+		{
+			name: "rented current property",
+			cp:   CurrentPropertyContext{MonthlyIncome: 1000, EnergyPerformanceRating: "F"},
+			good: Property{Name: "maison-a", EnergyPerformanceRating: "G"},
+			restrictions: []RentalRestriction{
+				{Scenario: rentalScenarioCurrentProperty, Rating: "F", BanYear: 2028, RentFrozen: true},
+			},
+			alerts: []Alert{
+				newAlert(alertRentalBan, severityError, "Renting the current property is banned from 2028 (DPE F)"),
+				newAlert(alertRentFrozen, severityWarning, "Rents of the current property are frozen (DPE F)"),
+			},
+		},
+		{
+			name: "current property not rented",
+			cp:   CurrentPropertyContext{EnergyPerformanceRating: "F"},
+			good: Property{Name: "maison-a", EnergyPerformanceRating: "G"},
+		},
+		{
+			// the current property is kept as our home, only the good is rented
+			name: "investment",
+			cp:   CurrentPropertyContext{MonthlyIncome: 1000, EnergyPerformanceRating: "F"},
+			good: Property{Name: "studio", EnergyPerformanceRating: "G", EnergyPerformanceRatingAfterRenovation: "E", Investment: &RentalInvestment{MonthlyRent: 600}},
+			restrictions: []RentalRestriction{
+				{Scenario: rentalScenarioInvestment, Rating: "G", RatingAfterRenovation: "E", BanYear: 2034},
+			},
+			alerts: []Alert{newAlert(alertRentalBan, severityError, `Renting "studio" is banned from 2034 (DPE E)`)},
+		},
+	}
+	for _, tt := range tests {
+		restrictions, alerts := rentalRestrictions(tt.cp, tt.good)
+		if !reflect.DeepEqual(restrictions, tt.restrictions) {
+			t.Errorf("%s: restrictions = %+v, want %+v", tt.name, restrictions, tt.restrictions)
+		}
+		if !reflect.DeepEqual(alerts, tt.alerts) {
+			t.Errorf("%s: alerts = %+v, want %+v", tt.name, alerts, tt.alerts)
+		}
+	}
+}
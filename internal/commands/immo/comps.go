@@ -0,0 +1,408 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var compsCmd = &cobra.Command{
+	Use:   "comps <good>",
+	Short: "Find the comparable sales of a good in DVF files, and estimate its fair value.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runComps,
+}
+
+var (
+	compsOutput           string
+	compsFiles            []string
+	compsMonths           int
+	compsSurfaceTolerance float64
+	compsRoomTolerance    int
+	compsLimit            int
+)
+
+func init() {
+	compsCmd.Flags().StringVarP(&compsOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	compsCmd.Flags().StringSliceVar(&compsFiles, "dvf", nil, "DVF files to search (default: comparables.files)")
+	compsCmd.Flags().IntVar(&compsMonths, "months", 0, "Only keep the sales of the last months of the files (default: comparables.months)")
+	compsCmd.Flags().Float64Var(&compsSurfaceTolerance, "surface-tolerance", 0, "Relative difference of surface accepted, e.g. 0.2 for ±20% (default: comparables.surface_tolerance)")
+	compsCmd.Flags().IntVar(&compsRoomTolerance, "room-tolerance", -1, "Difference of rooms accepted (default: comparables.room_tolerance)")
+	compsCmd.Flags().IntVar(&compsLimit, "limit", 20, "Maximum number of comparable sales listed, all of them are used for the fair value")
+}
+
+// Default criteria of the comparable sales.
+const (
+	defaultComparableMonths           = 24
+	defaultComparableSurfaceTolerance = 0.2
+	defaultComparableRoomTolerance    = 1
+	defaultComparableMinSales         = 3
+)
+
+// ComparablesConfig configures the search of the comparable sales of the goods, in files of the
+// Demandes de Valeurs Foncières (DVF) downloaded beforehand.
+type ComparablesConfig struct {
+	// Files are the DVF files to search, geo-dvf or raw DGFiP files, relative to the directory of
+	// immo.yaml. When they are set, evaluate shows the fair value of the goods.
+	Files []string `yaml:"files"`
+
+	// Months is the period of the sales kept, counted back from the latest sale of the files, as
+	// the DVF files are published with a delay. Defaults to 24.
+	Months int `yaml:"months"`
+
+	// SurfaceTolerance is the relative difference of surface accepted, e.g. 0.2 for ±20%.
+	SurfaceTolerance float64 `yaml:"surface_tolerance"`
+
+	// RoomTolerance is the difference of rooms accepted. Defaults to 1.
+	RoomTolerance *int `yaml:"room_tolerance"`
+
+	// MinSales is the minimum number of comparable sales to estimate a fair value. Defaults to 3.
+	MinSales int `yaml:"min_sales"`
+}
+
+func (c ComparablesConfig) withDefaults() ComparablesConfig {
+	roomTolerance := defaultComparableRoomTolerance
+	if c.RoomTolerance != nil {
+		roomTolerance = *c.RoomTolerance
+	}
+	return ComparablesConfig{
+		Files:            c.Files,
+		Months:           orDefault(c.Months, defaultComparableMonths),
+		SurfaceTolerance: orDefault(c.SurfaceTolerance, defaultComparableSurfaceTolerance),
+		RoomTolerance:    &roomTolerance,
+		MinSales:         orDefault(c.MinSales, defaultComparableMinSales),
+	}
+}
+
+// Comparable is a sale comparable to a good.
+type Comparable struct {
+	Date       string  `yaml:"date" json:"date"`
+	Address    string  `yaml:"address" json:"address"`
+	Price      float64 `yaml:"price" json:"price"`
+	SurfaceM2  float64 `yaml:"surface_m2" json:"surface_m2"`
+	Rooms      int     `yaml:"rooms" json:"rooms"`
+	PricePerM2 float64 `yaml:"price_per_m2" json:"price_per_m2"`
+	DistanceM  float64 `yaml:"distance_m,omitempty" json:"distance_m,omitempty"` // unknown without coordinates
+}
+
+// FairValue is the range of values of a good, from the prices per m² of its comparable sales: the
+// first quartile, the median and the third quartile, applied to the surface of the good.
+type FairValue struct {
+	Comparables      int     `yaml:"comparables" json:"comparables"`
+	PricePerM2Q1     float64 `yaml:"price_per_m2_q1" json:"price_per_m2_q1"`
+	PricePerM2Median float64 `yaml:"price_per_m2_median" json:"price_per_m2_median"`
+	PricePerM2Q3     float64 `yaml:"price_per_m2_q3" json:"price_per_m2_q3"`
+	Low              float64 `yaml:"low" json:"low"`
+	Median           float64 `yaml:"median" json:"median"`
+	High             float64 `yaml:"high" json:"high"`
+	Position         string  `yaml:"position" json:"position"` // price below, within or above the range
+}
+
+// ComparablesReport is the list of the comparable sales of a good.
+type ComparablesReport struct {
+	Good        string       `yaml:"good" json:"good"`
+	Type        string       `yaml:"type" json:"type"`
+	ZipCode     string       `yaml:"zip_code" json:"zip_code"`
+	Commune     string       `yaml:"commune" json:"commune"` // INSEE code of the sales
+	SurfaceM2   float64      `yaml:"surface_m2" json:"surface_m2"`
+	Rooms       int          `yaml:"rooms" json:"rooms"`
+	Price       float64      `yaml:"price" json:"price"`
+	Since       string       `yaml:"since" json:"since"`
+	Comparables []Comparable `yaml:"comparables" json:"comparables"`
+	FairValue   *FairValue   `yaml:"fair_value,omitempty" json:"fair_value,omitempty"`
+}
+
+func runComps(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(compsOutput)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+	var good *Property
+	for i := range cfg.Goods {
+		if cfg.Goods[i].Name == args[0] {
+			good = &cfg.Goods[i]
+		}
+	}
+	if good == nil {
+		return fmt.Errorf("good %q not found", args[0])
+	}
+
+	criteria := cfg.Comparables
+	if len(compsFiles) > 0 {
+		criteria.Files = compsFiles
+	}
+	if compsMonths > 0 {
+		criteria.Months = compsMonths
+	}
+	if compsSurfaceTolerance > 0 {
+		criteria.SurfaceTolerance = compsSurfaceTolerance
+	}
+	if compsRoomTolerance >= 0 {
+		criteria.RoomTolerance = &compsRoomTolerance
+	}
+	criteria = criteria.withDefaults()
+	if len(criteria.Files) == 0 {
+		return errors.New("no DVF file, set comparables.files or --dvf")
+	}
+	sales, err := loadSales(criteria.Files)
+	if err != nil {
+		return err
+	}
+
+	var (
+		comparables, since = findComparables(*good, sales, criteria)
+		report             = ComparablesReport{
+			Good:      good.Name,
+			Type:      good.Type,
+			ZipCode:   good.ZipCode,
+			Commune:   communeOf(*good, sales),
+			SurfaceM2: good.surface(),
+			Rooms:     good.RoomCount,
+			Price:     good.Price,
+			FairValue: fairValueOf(*good, comparables, criteria.MinSales),
+		}
+	)
+	if !since.IsZero() {
+		report.Since = since.Format(time.DateOnly)
+	}
+	report.Comparables = comparables
+	if compsLimit > 0 && len(report.Comparables) > compsLimit {
+		report.Comparables = report.Comparables[:compsLimit]
+	}
+
+	switch format {
+	case outputYAML, outputJSON:
+		return writeDocument(os.Stdout, format, report)
+	case outputCSV:
+		return writeTable(os.Stdout, format, report.table())
+	default:
+		if format == outputMarkdown {
+			fmt.Printf("## %s\n\n", report.Good)
+		} else {
+			fmt.Printf("Comparable sales of %q: %s of %.0f m², %d rooms in %s (commune %s), since %s\n", report.Good, report.Type, report.SurfaceM2, report.Rooms, report.ZipCode, report.Commune, report.Since)
+			fmt.Println("==========")
+		}
+		if err := writeTable(os.Stdout, format, report.table()); err != nil {
+			return err
+		}
+		fmt.Println()
+		if f := report.FairValue; f != nil {
+			fmt.Printf("Fair value from %d sales: %s - %s - %s (%s - %s - %s per m²), the price %s is %s the range\n",
+				f.Comparables,
+				formatAmount(f.Low), formatAmount(f.Median), formatAmount(f.High),
+				formatAmount(f.PricePerM2Q1), formatAmount(f.PricePerM2Median), formatAmount(f.PricePerM2Q3),
+				formatAmount(report.Price), f.Position)
+		} else {
+			fmt.Printf("Not enough comparable sales to estimate a fair value (%d < %d)\n", len(comparables), criteria.MinSales)
+		}
+		return nil
+	}
+}
+
+// loadSales reads the sales of single houses and apartments from DVF files. The relative paths are
+// relative to the directory of immo.yaml.
+func loadSales(files []string) ([]dvfTransaction, error) {
+	var (
+		sales    []dvfTransaction
+		excluded DVFExclusions
+	)
+	for _, file := range files {
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(os.Getenv("JIMI_CONFIG"), file)
+		}
+		fmt.Fprintf(os.Stderr, "Reading %s\n", file)
+		rows, err := readDVF(file)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, dvfTransactions(rows, time.Time{}, time.Time{}, &excluded)...)
+	}
+	return sales, nil
+}
+
+// surface returns the living space of the good, comparable to the built surface of the DVF
+// sales.
+func (p Property) surface() float64 {
+	if p.TotalLivingSpaceM2 > 0 {
+		return p.TotalLivingSpaceM2
+	}
+	return p.LivingSpaceLoiCarrezM2
+}
+
+// communeOf returns the INSEE code of the commune of the good: its configured commune, or else the
+// commune of most of the sales of its zip code, as a zip code can span several communes.
+func communeOf(good Property, sales []dvfTransaction) string {
+	if good.Commune != "" {
+		return good.Commune
+	}
+	communes := make(map[string]int)
+	for _, s := range sales {
+		if s.zipCode == good.ZipCode {
+			communes[s.commune]++
+		}
+	}
+	return mostFrequent(communes)
+}
+
+// findComparables returns the recent sales of the same type of good in the same commune, with a
+// similar surface and number of rooms, the closest first and the ones at an unknown distance last.
+// It also returns the date of the oldest sale kept.
+func findComparables(good Property, sales []dvfTransaction, criteria ComparablesConfig) ([]Comparable, time.Time) {
+	var latest time.Time
+	for _, s := range sales {
+		if s.date.After(latest) {
+			latest = s.date
+		}
+	}
+	if latest.IsZero() {
+		return nil, latest
+	}
+	var (
+		since       = latest.AddDate(0, -criteria.Months, 0)
+		commune     = communeOf(good, sales)
+		surface     = good.surface()
+		comparables []Comparable
+		dates       []time.Time
+	)
+	for _, s := range sales {
+		if s.goodType != good.Type || s.commune != commune || s.date.Before(since) {
+			continue
+		}
+		if math.Abs(s.surface-surface) > surface*criteria.SurfaceTolerance {
+			continue
+		}
+		if good.RoomCount > 0 && abs(s.rooms-good.RoomCount) > *criteria.RoomTolerance {
+			continue
+		}
+		c := Comparable{
+			Date:       s.date.Format(time.DateOnly),
+			Address:    s.address,
+			Price:      math.Round(s.price),
+			SurfaceM2:  s.surface,
+			Rooms:      s.rooms,
+			PricePerM2: math.Round(s.pricePerM2),
+		}
+		if good.Latitude != 0 && good.Longitude != 0 && s.latitude != 0 && s.longitude != 0 {
+			// a sale at the same address is 1 m away, 0 is an unknown distance
+			c.DistanceM = math.Max(math.Round(haversine(good.Latitude, good.Longitude, s.latitude, s.longitude)), 1)
+		}
+		comparables = append(comparables, c)
+		dates = append(dates, s.date)
+	}
+	sort.SliceStable(comparables, func(i, j int) bool {
+		a, b := comparables[i], comparables[j]
+		if (a.DistanceM == 0) != (b.DistanceM == 0) {
+			return b.DistanceM == 0
+		}
+		if a.DistanceM != b.DistanceM {
+			return a.DistanceM < b.DistanceM
+		}
+		return a.Date > b.Date
+	})
+	return comparables, since
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// earthRadiusM is the mean radius of the Earth in meters.
+const earthRadiusM = 6371000
+
+// haversine returns the distance in meters between two points given by their latitude and
+// longitude in degrees.
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	var (
+		toRad = math.Pi / 180
+		dLat  = (lat2 - lat1) * toRad
+		dLon  = (lon2 - lon1) * toRad
+		a     = math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	)
+	return 2 * earthRadiusM * math.Asin(math.Sqrt(a))
+}
+
+// fairValuesOf returns the fair values of the goods keyed by their name, the goods without enough
+// comparable sales being missing. They do not depend on the financing, so they are computed once
+// for all the evaluations.
+func fairValuesOf(goods []Property, sales []dvfTransaction, criteria ComparablesConfig) map[string]*FairValue {
+	fairValues := make(map[string]*FairValue)
+	for _, good := range goods {
+		comparables, _ := findComparables(good, sales, criteria)
+		if f := fairValueOf(good, comparables, criteria.MinSales); f != nil {
+			fairValues[good.Name] = f
+		}
+	}
+	return fairValues
+}
+
+// fairValueOf returns the fair value range of the good from its comparable sales, without their
+// outliers. It returns nil when there are not enough comparable sales.
+func fairValueOf(good Property, comparables []Comparable, minSales int) *FairValue {
+	prices := make([]float64, len(comparables))
+	for i, c := range comparables {
+		prices[i] = c.PricePerM2
+	}
+	prices = withoutOutliers(prices)
+	if len(prices) < minSales || len(prices) == 0 {
+		return nil
+	}
+	var (
+		d       = priceDistribution(prices)
+		surface = good.surface()
+		f       = FairValue{
+			Comparables:      d.Sales,
+			PricePerM2Q1:     d.Q1,
+			PricePerM2Median: d.Median,
+			PricePerM2Q3:     d.Q3,
+			Low:              math.Round(d.Q1 * surface),
+			Median:           math.Round(d.Median * surface),
+			High:             math.Round(d.Q3 * surface),
+		}
+	)
+	return f.withPrice(good.Price)
+}
+
+// withPrice returns a copy of the fair value positioned against the price of the good.
+func (f FairValue) withPrice(price float64) *FairValue {
+	f.Position = "within"
+	switch {
+	case price < f.Low:
+		f.Position = "below"
+	case price > f.High:
+		f.Position = "above"
+	}
+	return &f
+}
+
+func (r ComparablesReport) table() table {
+	t := table{headers: []string{"date", "address", "price", "surface_m2", "rooms", "price_per_m2", "distance_m"}}
+	for _, c := range r.Comparables {
+		var distance string
+		if c.DistanceM > 0 {
+			distance = formatAmount(c.DistanceM)
+		}
+		t.append(
+			c.Date,
+			c.Address,
+			formatAmount(c.Price),
+			fmt.Sprintf("%g", c.SurfaceM2),
+			fmt.Sprint(c.Rooms),
+			formatAmount(c.PricePerM2),
+			distance,
+		)
+	}
+	return t
+}
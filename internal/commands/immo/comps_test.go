@@ -0,0 +1,84 @@
+package immo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFindComparables(t *testing.T) {
+	var (
+		good = Property{
+			Name:               "flat",
+			Type:               "apartment",
+			ZipCode:            "92330",
+			TotalLivingSpaceM2: 50,
+			RoomCount:          2,
+			Price:              400000,
+			Latitude:           48.7785,
+			Longitude:          2.2905,
+		}
+		date  = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		sales = []dvfTransaction{
+			{address: "far", date: date, zipCode: "92330", commune: "92071", goodType: "apartment", surface: 50, rooms: 2, pricePerM2: 7000, latitude: 48.7850, longitude: 2.2905},
+			{address: "unknown", date: date, zipCode: "92330", commune: "92071", goodType: "apartment", surface: 48, rooms: 2, pricePerM2: 7200},
+			{address: "same building", date: date, zipCode: "92330", commune: "92071", goodType: "apartment", surface: 52, rooms: 3, pricePerM2: 7400, latitude: 48.7785, longitude: 2.2905},
+			{address: "near", date: date, zipCode: "92330", commune: "92071", goodType: "apartment", surface: 45, rooms: 2, pricePerM2: 7600, latitude: 48.7790, longitude: 2.2905},
+			// another commune of the zip code, and a cedex of the commune
+			{address: "other commune", date: date, zipCode: "92330", commune: "92046", goodType: "apartment", surface: 50, rooms: 2, pricePerM2: 5000},
+			{address: "cedex", date: date, zipCode: "92331", commune: "92071", goodType: "apartment", surface: 50, rooms: 2, pricePerM2: 7100},
+			// not comparable
+			{address: "house", date: date, zipCode: "92330", commune: "92071", goodType: "house", surface: 50, rooms: 2, pricePerM2: 7000},
+			{address: "large", date: date, zipCode: "92330", commune: "92071", goodType: "apartment", surface: 80, rooms: 2, pricePerM2: 7000},
+			{address: "old", date: date.AddDate(-3, 0, 0), zipCode: "92330", commune: "92071", goodType: "apartment", surface: 50, rooms: 2, pricePerM2: 7000},
+		}
+		criteria = ComparablesConfig{}.withDefaults()
+	)
+	comparables, since := findComparables(good, sales, criteria)
+	if want := date.AddDate(0, -24, 0); !since.Equal(want) {
+		t.Errorf("since = %s, want %s", since.Format(time.DateOnly), want.Format(time.DateOnly))
+	}
+	// the closest first, the cedex and the unknown distance last, the most recent first
+	want := []string{"same building", "near", "far", "unknown", "cedex"}
+	if len(comparables) != len(want) {
+		t.Fatalf("findComparables() = %+v, want %v", comparables, want)
+	}
+	for i, address := range want {
+		if comparables[i].Address != address {
+			t.Errorf("comparable %d = %q, want %q", i, comparables[i].Address, address)
+		}
+	}
+	if comparables[0].DistanceM != 1 {
+		t.Errorf("distance of the same building = %.0f, want 1", comparables[0].DistanceM)
+	}
+
+	// the configured commune wins over the commune of most of the sales of the zip code
+	good.Commune = "92046"
+	if comparables, _ := findComparables(good, sales, criteria); len(comparables) != 1 || comparables[0].Address != "other commune" {
+		t.Errorf("findComparables() in 92046 = %+v, want the other commune", comparables)
+	}
+}
+
+func TestFairValuesOf(t *testing.T) {
+	var (
+		good  = Property{Name: "flat", Type: "apartment", ZipCode: "92330", TotalLivingSpaceM2: 50, Price: 400000}
+		date  = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+		sales []dvfTransaction
+	)
+	for _, price := range []float64{6000, 6500, 7000, 7500, 8000} {
+		sales = append(sales, dvfTransaction{date: date, zipCode: "92330", commune: "92071", goodType: "apartment", surface: 50, pricePerM2: price})
+	}
+	fairValues := fairValuesOf([]Property{good, {Name: "elsewhere", Type: "apartment", ZipCode: "75001", TotalLivingSpaceM2: 50}}, sales, ComparablesConfig{}.withDefaults())
+	if len(fairValues) != 1 {
+		t.Fatalf("fairValuesOf() = %v, want only the fair value of flat", fairValues)
+	}
+	want := FairValue{Comparables: 5, PricePerM2Q1: 6500, PricePerM2Median: 7000, PricePerM2Q3: 7500, Low: 325000, Median: 350000, High: 375000, Position: "above"}
+	if got := *fairValues["flat"]; got != want {
+		t.Errorf("fair value = %+v, want %+v", got, want)
+	}
+	// the range does not depend on the price, only the position
+	for price, position := range map[float64]string{300000: "below", 350000: "within", 375000: "within"} {
+		if got := fairValues["flat"].withPrice(price); got.Position != position || got.High != want.High {
+			t.Errorf("withPrice(%.0f) = %+v, want %s", price, got, position)
+		}
+	}
+}
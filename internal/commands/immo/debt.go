@@ -0,0 +1,77 @@
+package immo
+
+import "math"
+
+const (
+	// defaultDebtRatioLimit is the maximum debt-to-income ratio set by the HCSF (Haut Conseil de
+	// stabilité financière), insurance included.
+	defaultDebtRatioLimit = 0.35
+	// rentalIncomeWeight is the share of rental incomes taken into account by banks, to cover
+	// vacancy and unpaid rents.
+	rentalIncomeWeight = 0.70
+)
+
+// Borrower is a person borrowing for the purchase.
+type Borrower struct {
+	Name string `yaml:"name"`
+
+	// MonthlyNetIncome is the monthly net income before income tax, as shown on the pay slips.
+	MonthlyNetIncome float64 `yaml:"monthly_net_income"`
+}
+
+// MonthlyNetIncome returns the total monthly net income of the borrowers.
+func (f FamilyContext) MonthlyNetIncome() float64 {
+	var total float64
+	for _, b := range f.Borrowers {
+		total += b.MonthlyNetIncome
+	}
+	return total
+}
+
+func (f FamilyContext) debtRatioLimit() float64 {
+	if f.DebtRatioLimit > 0 {
+		return f.DebtRatioLimit
+	}
+	return defaultDebtRatioLimit
+}
+
+// computeDebtRatio returns the debt-to-income ratio (taux d'endettement) of the family, as
+// computed by banks: the loan payments, insurance included, divided by the net incomes and 70% of
+// the rental incomes.
+func computeDebtRatio(family FamilyContext, monthlyRentalIncome, monthlyLoanPayments float64) DebtRatio {
+	var (
+		weightedRentalIncome = monthlyRentalIncome * rentalIncomeWeight
+		income               = family.MonthlyNetIncome() + weightedRentalIncome
+		payments             = monthlyLoanPayments + family.OtherMonthlyLoanPayments
+		ratio                float64
+	)
+	if income > 0 {
+		ratio = payments / income
+	} else {
+		ratio = math.Inf(1)
+	}
+	return DebtRatio{
+		MonthlyNetIncome:     family.MonthlyNetIncome(),
+		WeightedRentalIncome: weightedRentalIncome,
+		MonthlyLoanPayments:  payments,
+		Ratio:                ratio,
+		Limit:                family.debtRatioLimit(),
+	}
+}
+
+// Exceeded indicates if the ratio breaks the limit.
+func (d DebtRatio) Exceeded() bool {
+	return d.Ratio > d.Limit
+}
+
+// rounded returns a copy of the debt ratio with amounts rounded to the euro and the ratio rounded
+// to 0.1%, for display.
+func (d DebtRatio) rounded() DebtRatio {
+	return DebtRatio{
+		MonthlyNetIncome:     math.Round(d.MonthlyNetIncome),
+		WeightedRentalIncome: math.Round(d.WeightedRentalIncome),
+		MonthlyLoanPayments:  math.Round(d.MonthlyLoanPayments),
+		Ratio:                math.Round(d.Ratio*1000) / 1000,
+		Limit:                d.Limit,
+	}
+}
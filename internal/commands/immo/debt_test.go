@@ -0,0 +1,63 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestComputeDebtRatio(t *testing.T) {
+	family := FamilyContext{
+		Borrowers:                []Borrower{{Name: "A", MonthlyNetIncome: 4000}, {Name: "B", MonthlyNetIncome: 2000}},
+		OtherMonthlyLoanPayments: 200,
+	}
+	tests := []struct {
+		name     string
+		family   FamilyContext
+		rents    float64
+		payments float64
+		want     DebtRatio
+		exceeded bool
+	}{
+		{
+			name:     "incomes only",
+			family:   family,
+			payments: 1900,
+			want:     DebtRatio{MonthlyNetIncome: 6000, MonthlyLoanPayments: 2100, Ratio: 0.35, Limit: 0.35},
+		},
+		{
+			// 70% of the rents are added to the incomes
+			name:     "rents weighted",
+			family:   family,
+			rents:    1000,
+			payments: 2100,
+			want:     DebtRatio{MonthlyNetIncome: 6000, WeightedRentalIncome: 700, MonthlyLoanPayments: 2300, Ratio: 0.343, Limit: 0.35},
+		},
+		{
+			name:     "above the limit",
+			family:   family,
+			payments: 2000,
+			want:     DebtRatio{MonthlyNetIncome: 6000, MonthlyLoanPayments: 2200, Ratio: 0.367, Limit: 0.35},
+			exceeded: true,
+		},
+		{
+			name:     "custom limit",
+			family:   FamilyContext{Borrowers: family.Borrowers, DebtRatioLimit: 0.40},
+			payments: 2200,
+			want:     DebtRatio{MonthlyNetIncome: 6000, MonthlyLoanPayments: 2200, Ratio: 0.367, Limit: 0.40},
+		},
+	}
+	for _, tt := range tests {
+		got := computeDebtRatio(tt.family, tt.rents, tt.payments)
+		if got.Exceeded() != tt.exceeded {
+			t.Errorf("%s: Exceeded() = %t, want %t", tt.name, got.Exceeded(), tt.exceeded)
+		}
+		if got := got.rounded(); got != tt.want {
+			t.Errorf("%s: computeDebtRatio() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	// without income, any loan exceeds the limit
+	if got := computeDebtRatio(FamilyContext{}, 0, 1000); !math.IsInf(got.Ratio, 1) || !got.Exceeded() {
+		t.Errorf("computeDebtRatio() without income = %+v, want an infinite ratio", got)
+	}
+}
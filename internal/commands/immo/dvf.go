@@ -0,0 +1,367 @@
+package immo
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file reads the open data of the Demandes de Valeurs Foncières (DVF), the real-estate sales
+// registered by the DGFiP. Two formats are supported:
+//
+//   - the geolocated files of Etalab (geo-dvf), comma-separated with a row per parcel and local,
+//     e.g. 92.csv.gz downloaded from files.data.gouv.fr/geo-dvf;
+//   - the raw files of the DGFiP, pipe-separated, e.g. ValeursFoncieres-2023.txt.
+//
+// A sale (mutation) spans several rows, one per parcel and per local sold.
+
+// Codes of the types of locals in DVF. The dependencies (3), e.g. a garage or a cellar, are sold
+// with the dwellings and kept in their sales.
+const (
+	dvfHouse      = "1"
+	dvfApartment  = "2"
+	dvfCommercial = "4" // local industriel, commercial ou assimilé
+)
+
+// dvfSale is the only nature of mutation kept: the sales of existing goods. The sales before
+// completion (VEFA), auctions and exchanges have different prices.
+const dvfSale = "Vente"
+
+// dvfRow is a row of a DVF file, in a format-independent representation.
+type dvfRow struct {
+	mutation    string // identifier of the sale
+	date        time.Time
+	nature      string
+	value       float64 // price of the whole sale
+	zipCode     string
+	commune     string // INSEE code
+	communeName string
+	localType   string // code of the type of local, empty for a bare land
+	surface     float64
+	rooms       int
+	address     string
+	longitude   float64 // 0 when the format is not geolocated
+	latitude    float64
+	local       string // identifier of the local, a sale has a row per parcel of each local
+}
+
+// dvfColumns are the names of the columns of each format.
+type dvfColumns struct {
+	comma       rune
+	mutation    string // empty when the format has no identifier of sale
+	date        string
+	dateLayout  string
+	nature      string
+	value       string
+	zipCode     string
+	commune     string
+	communeName string
+	department  string
+	disposition string
+	localType   string
+	surface     string
+	rooms       string
+	number      string
+	street      string
+	longitude   string
+	latitude    string
+}
+
+var (
+	geoDVFColumns = dvfColumns{
+		comma:       ',',
+		mutation:    "id_mutation",
+		date:        "date_mutation",
+		dateLayout:  "2006-01-02",
+		nature:      "nature_mutation",
+		value:       "valeur_fonciere",
+		zipCode:     "code_postal",
+		commune:     "code_commune",
+		communeName: "nom_commune",
+		localType:   "code_type_local",
+		surface:     "surface_reelle_bati",
+		rooms:       "nombre_pieces_principales",
+		number:      "adresse_numero",
+		street:      "adresse_nom_voie",
+		longitude:   "longitude",
+		latitude:    "latitude",
+	}
+	rawDVFColumns = dvfColumns{
+		comma:       '|',
+		date:        "Date mutation",
+		dateLayout:  "02/01/2006",
+		nature:      "Nature mutation",
+		value:       "Valeur fonciere",
+		zipCode:     "Code postal",
+		commune:     "Code commune",
+		communeName: "Commune",
+		department:  "Code departement",
+		disposition: "No disposition",
+		localType:   "Code type local",
+		surface:     "Surface reelle bati",
+		rooms:       "Nombre pieces principales",
+		number:      "No voie",
+		street:      "Voie",
+	}
+)
+
+// readDVF reads the rows of a DVF file, compressed with gzip or not. The format is detected from
+// the header.
+func readDVF(path string) ([]dvfRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var r io.Reader = bufio.NewReader(f)
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	br := bufio.NewReader(r)
+	header, err := br.Peek(512)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	columns := geoDVFColumns
+	if firstLine, _, _ := strings.Cut(string(header), "\n"); strings.Contains(firstLine, "|") {
+		columns = rawDVFColumns
+	}
+
+	reader := csv.NewReader(br)
+	reader.Comma = columns.comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	names, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read the header: %w", path, err)
+	}
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	for _, name := range []string{columns.date, columns.nature, columns.value, columns.zipCode, columns.commune, columns.localType, columns.surface} {
+		if _, exists := index[name]; !exists {
+			return nil, fmt.Errorf("%s: column %q not found, is it a DVF file?", path, name)
+		}
+	}
+
+	var rows []dvfRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		field := func(name string) string {
+			if i, exists := index[name]; exists && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		date, err := time.Parse(columns.dateLayout, field(columns.date))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid date %q", path, line, field(columns.date))
+		}
+		row := dvfRow{
+			mutation:    field(columns.mutation),
+			date:        date,
+			nature:      field(columns.nature),
+			value:       dvfNumber(field(columns.value)),
+			zipCode:     zeroPad(field(columns.zipCode), 5),
+			commune:     field(columns.commune),
+			communeName: field(columns.communeName),
+			localType:   field(columns.localType),
+			surface:     dvfNumber(field(columns.surface)),
+			rooms:       int(dvfNumber(field(columns.rooms))),
+			address:     strings.TrimSpace(field(columns.number) + " " + field(columns.street)),
+			longitude:   dvfNumber(field(columns.longitude)),
+			latitude:    dvfNumber(field(columns.latitude)),
+		}
+		if columns.mutation == "" {
+			// the raw files have no identifier of sale, and the code of the commune is relative
+			// to its department, e.g. 92 and 012, or 971 and 101 for the overseas departments
+			// whose code of commune already holds the third digit
+			department := zeroPad(field(columns.department), 2)
+			if len(department) > 2 {
+				department = department[:2]
+			}
+			row.commune = department + zeroPad(row.commune, 3)
+			row.mutation = strings.Join([]string{field(columns.date), field(columns.value), row.commune, field(columns.disposition)}, "|")
+		}
+		if row.localType != "" {
+			row.local = strings.Join([]string{row.localType, field(columns.surface), field(columns.rooms), field(columns.number), field(columns.street)}, "|")
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// dvfNumber parses a number of a DVF file, with a comma or a dot as decimal separator. It returns
+// 0 when the field is empty.
+func dvfNumber(s string) float64 {
+	f, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
+
+// zeroPad restores the leading zeros dropped from a code, e.g. 1000 for the zip code 01000.
+func zeroPad(s string, length int) string {
+	if s == "" || len(s) >= length {
+		return s
+	}
+	return strings.Repeat("0", length-len(s)) + s
+}
+
+// dvfTransaction is a sale of a single house or apartment, possibly with its dependencies.
+type dvfTransaction struct {
+	date        time.Time
+	zipCode     string
+	commune     string
+	communeName string
+	address     string
+	goodType    string // house or apartment
+	price       float64
+	surface     float64
+	rooms       int
+	pricePerM2  float64
+	longitude   float64
+	latitude    float64
+}
+
+// DVFExclusions counts the sales excluded from the statistics, by reason.
+type DVFExclusions struct {
+	OutOfPeriod int `yaml:"out_of_period" json:"out_of_period"`
+	NotASale    int `yaml:"not_a_sale" json:"not_a_sale"`   // e.g. VEFA, auction or exchange
+	NoDwelling  int `yaml:"no_dwelling" json:"no_dwelling"` // e.g. land, parking or shop
+	Mixed       int `yaml:"mixed" json:"mixed"`             // several dwellings, or a dwelling with a shop
+	NoSurface   int `yaml:"no_surface" json:"no_surface"`   // price or surface missing
+	Outliers    int `yaml:"outliers" json:"outliers"`
+}
+
+// dvfTransactions groups the rows by sale, and keeps the sales of a single house or apartment in
+// the period. A zero time means no bound.
+func dvfTransactions(rows []dvfRow, from, to time.Time, excluded *DVFExclusions) []dvfTransaction {
+	var (
+		order     []string
+		mutations = make(map[string][]dvfRow)
+	)
+	for _, row := range rows {
+		if _, exists := mutations[row.mutation]; !exists {
+			order = append(order, row.mutation)
+		}
+		mutations[row.mutation] = append(mutations[row.mutation], row)
+	}
+
+	var transactions []dvfTransaction
+	for _, id := range order {
+		var (
+			mutation  = mutations[id]
+			first     = mutation[0]
+			dwellings = make(map[string]dvfRow)
+			dwelling  dvfRow
+			mixed     bool
+		)
+		if (!from.IsZero() && first.date.Before(from)) || (!to.IsZero() && first.date.After(to)) {
+			excluded.OutOfPeriod++
+			continue
+		}
+		if first.nature != dvfSale {
+			excluded.NotASale++
+			continue
+		}
+		for _, row := range mutation {
+			switch row.localType {
+			case dvfHouse, dvfApartment:
+				dwellings[row.local] = row
+				dwelling = row
+			case dvfCommercial:
+				mixed = true
+			}
+		}
+		if len(dwellings) == 0 {
+			excluded.NoDwelling++
+			continue
+		}
+		if mixed || len(dwellings) > 1 {
+			excluded.Mixed++
+			continue
+		}
+		if first.value <= 0 || dwelling.surface <= 0 {
+			excluded.NoSurface++
+			continue
+		}
+		goodType := "house"
+		if dwelling.localType == dvfApartment {
+			goodType = "apartment"
+		}
+		transactions = append(transactions, dvfTransaction{
+			date:        first.date,
+			zipCode:     dwelling.zipCode,
+			commune:     dwelling.commune,
+			communeName: dwelling.communeName,
+			address:     dwelling.address,
+			goodType:    goodType,
+			price:       first.value,
+			surface:     dwelling.surface,
+			rooms:       dwelling.rooms,
+			pricePerM2:  first.value / dwelling.surface,
+			longitude:   dwelling.longitude,
+			latitude:    dwelling.latitude,
+		})
+	}
+	return transactions
+}
+
+// PriceDistribution is the distribution of the prices per m² of the sales of a type of good.
+type PriceDistribution struct {
+	Sales  int     `yaml:"sales" json:"sales"`
+	Q1     float64 `yaml:"q1" json:"q1"`
+	Median float64 `yaml:"median" json:"median"`
+	Q3     float64 `yaml:"q3" json:"q3"`
+}
+
+// withoutOutliers returns the prices within the fences of Tukey: 1.5 interquartile range below the
+// first quartile and above the third quartile. The prices are sorted in place.
+func withoutOutliers(prices []float64) []float64 {
+	sort.Float64s(prices)
+	var (
+		q1, q3 = percentile(prices, 0.25), percentile(prices, 0.75)
+		iqr    = q3 - q1
+		kept   []float64
+	)
+	for _, p := range prices {
+		if p >= q1-1.5*iqr && p <= q3+1.5*iqr {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// priceDistribution returns the quartiles of the sorted prices, rounded to the euro.
+func priceDistribution(sorted []float64) PriceDistribution {
+	return PriceDistribution{
+		Sales:  len(sorted),
+		Q1:     math.Round(percentile(sorted, 0.25)),
+		Median: math.Round(percentile(sorted, 0.5)),
+		Q3:     math.Round(percentile(sorted, 0.75)),
+	}
+}
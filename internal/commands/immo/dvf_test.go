@@ -0,0 +1,112 @@
+package immo
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func writeDVF(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadGeoDVF(t *testing.T) {
+	path := writeDVF(t, "92.csv", `id_mutation,date_mutation,nature_mutation,valeur_fonciere,adresse_numero,adresse_nom_voie,code_postal,code_commune,nom_commune,code_type_local,surface_reelle_bati,nombre_pieces_principales,longitude,latitude
+2023-1,2023-03-01,Vente,500000,1,RUE A,92160,92002,Antony,1,100,5,2.29,48.75
+2023-1,2023-03-01,Vente,500000,1,RUE A,92160,92002,Antony,1,100,5,2.29,48.75
+2023-1,2023-03-01,Vente,500000,1,RUE A,92160,92002,Antony,3,,,2.29,48.75
+2023-2,2023-04-01,Vente,300000,2,RUE B,92160,92002,Antony,2,60,3,,
+2023-2,2023-04-01,Vente,300000,2,RUE B,92160,92002,Antony,4,40,,,
+2023-3,2023-05-01,Vente,400000,3,RUE C,92160,92002,Antony,2,40,2,,
+2023-3,2023-05-01,Vente,400000,3,RUE C,92160,92002,Antony,2,45,2,,
+2023-4,2023-06-01,Vente en l'état futur d'achèvement,250000,4,RUE D,92160,92002,Antony,2,50,2,,
+2023-5,2023-07-01,Vente,100000,,RUE E,92160,92002,Antony,,,,,
+`)
+	rows, err := readDVF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 9 {
+		t.Fatalf("read %d rows, want 9", len(rows))
+	}
+
+	var excluded DVFExclusions
+	transactions := dvfTransactions(rows, time.Time{}, time.Time{}, &excluded)
+	want := dvfTransaction{
+		date:        time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC),
+		zipCode:     "92160",
+		commune:     "92002",
+		communeName: "Antony",
+		address:     "1 RUE A",
+		goodType:    "house",
+		price:       500000,
+		surface:     100,
+		rooms:       5,
+		pricePerM2:  5000,
+		longitude:   2.29,
+		latitude:    48.75,
+	}
+	// the two parcels of the house and its dependency are a single sale
+	if len(transactions) != 1 || transactions[0] != want {
+		t.Errorf("dvfTransactions() = %+v, want [%+v]", transactions, want)
+	}
+	if want := (DVFExclusions{NotASale: 1, NoDwelling: 1, Mixed: 2}); excluded != want {
+		t.Errorf("excluded = %+v, want %+v", excluded, want)
+	}
+
+	excluded = DVFExclusions{}
+	from := time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC)
+	if transactions := dvfTransactions(rows, from, time.Time{}, &excluded); len(transactions) != 0 || excluded.OutOfPeriod != 1 {
+		t.Errorf("dvfTransactions() from %s = %d transactions, %d out of period, want 0 and 1", from.Format(time.DateOnly), len(transactions), excluded.OutOfPeriod)
+	}
+}
+
+func TestReadRawDVF(t *testing.T) {
+	path := writeDVF(t, "ValeursFoncieres-2023.txt", `No disposition|Date mutation|Nature mutation|Valeur fonciere|No voie|Voie|Code postal|Commune|Code departement|Code commune|Code type local|Surface reelle bati|Nombre pieces principales
+000001|02/01/2023|Vente|150000,00|5|RUE DES ABYMES|97139|LES ABYMES|971|101|2|50|2
+000001|02/01/2023|Vente|150000,00|5|RUE DES ABYMES|97139|LES ABYMES|971|101|2|50|2
+000001|03/01/2023|Vente|450000,00|8|RUE DE SILLY|92100|BOULOGNE-BILLANCOURT|92|12|2|45|2
+000001|04/01/2023|Vente|200000,00|1|RUE DE LA PAIX|1000|BOURG-EN-BRESSE|01|53|1|80|4
+`)
+	rows, err := readDVF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var excluded DVFExclusions
+	transactions := dvfTransactions(rows, time.Time{}, time.Time{}, &excluded)
+	if len(transactions) != 3 {
+		t.Fatalf("dvfTransactions() = %d transactions, want 3", len(transactions))
+	}
+	tests := []struct {
+		zipCode, commune string
+		pricePerM2       float64
+	}{
+		{zipCode: "97139", commune: "97101", pricePerM2: 3000},
+		{zipCode: "92100", commune: "92012", pricePerM2: 10000},
+		{zipCode: "01000", commune: "01053", pricePerM2: 2500},
+	}
+	for i, tt := range tests {
+		if got := transactions[i]; got.zipCode != tt.zipCode || got.commune != tt.commune || got.pricePerM2 != tt.pricePerM2 {
+			t.Errorf("transaction %d = %s, %s, %.0f/m², want %s, %s, %.0f/m²", i, got.zipCode, got.commune, got.pricePerM2, tt.zipCode, tt.commune, tt.pricePerM2)
+		}
+	}
+}
+
+func TestWithoutOutliers(t *testing.T) {
+	// Q1 = 4075 and Q3 = 4425: the fences are 3550 and 4950
+	prices := []float64{9000, 4500, 4000, 4100, 1000, 4300, 4200, 4400}
+	if got, want := withoutOutliers(prices), []float64{4000, 4100, 4200, 4300, 4400, 4500}; !slices.Equal(got, want) {
+		t.Errorf("withoutOutliers() = %v, want %v", got, want)
+	}
+	// the prices within the fences are all kept
+	if got := withoutOutliers([]float64{3000, 3000, 3000}); len(got) != 3 {
+		t.Errorf("withoutOutliers() = %v, want all the prices", got)
+	}
+}
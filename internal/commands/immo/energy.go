@@ -0,0 +1,208 @@
+package immo
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Energies used for the heating.
+const (
+	energyElectric = "electric"
+	energyHeatPump = "heat_pump"
+	energyGas      = "gas"
+	energyFuel     = "fuel"
+	energyWood     = "wood"
+)
+
+// Default tariffs in euros per kWh, taxes included, overridable with EnergyConfig.Tariffs.
+var defaultEnergyTariffs = map[string]float64{
+	energyElectric: 0.20,
+	energyGas:      0.12,
+	energyFuel:     0.11,
+	energyWood:     0.08,
+}
+
+const (
+	// electricityPrimaryFactor converts the primary energy of the DPE into the electricity
+	// consumed (coefficient d'énergie primaire, 1.9 since 2026). Other energies have a factor of 1.
+	electricityPrimaryFactor = 1.9
+
+	// defaultHeatingShare is the share of the DPE consumption used by the heating, the rest being
+	// the hot water, the lighting, the ventilation and the cooling.
+	defaultHeatingShare = 0.7
+)
+
+// dpeConsumptions are the representative consumptions of each DPE letter in kWh of primary
+// energy per m² per year, in the middle of the range of the letter.
+var dpeConsumptions = map[string]float64{
+	"A": 50,
+	"B": 90,
+	"C": 145,
+	"D": 215,
+	"E": 290,
+	"F": 375,
+	"G": 480,
+}
+
+// EnergyConfig configures the estimation of the energy costs.
+type EnergyConfig struct {
+	// Tariffs are the prices of the energies in euros per kWh, keyed by energy: electric, gas,
+	// fuel and wood. The heat pumps use the electric tariff.
+	Tariffs map[string]float64 `yaml:"tariffs"`
+
+	// HeatingShare is the share of the consumption used by the heating. Default to 70%.
+	HeatingShare float64 `yaml:"heating_share"`
+}
+
+func energyNames() []string {
+	return []string{energyElectric, energyGas, energyFuel, energyWood}
+}
+
+func (c EnergyConfig) tariff(energy string) float64 {
+	if energy == energyHeatPump {
+		energy = energyElectric
+	}
+	if tariff, exists := c.Tariffs[energy]; exists {
+		return tariff
+	}
+	return defaultEnergyTariffs[energy]
+}
+
+func (c EnergyConfig) heatingShare() float64 {
+	if c.HeatingShare > 0 {
+		return c.HeatingShare
+	}
+	return defaultHeatingShare
+}
+
+// heatPumpNames are the names of a heat pump in listings. They are matched as whole words, since
+// "pac" is also a part of words like "capacité" or "espace".
+var heatPumpNames = []string{"pac", "pompe à chaleur", "pompe a chaleur", "heat pump"}
+
+// heatingEnergy returns the energy used by the heating type of a listing, e.g. "Gaz" or "pompe à
+// chaleur". It defaults to electric.
+func heatingEnergy(heatingType string) string {
+	t := strings.ToLower(heatingType)
+	switch {
+	case containsWords(t, heatPumpNames):
+		return energyHeatPump
+	case strings.Contains(t, "gas"), strings.Contains(t, "gaz"):
+		return energyGas
+	case strings.Contains(t, "fuel"), strings.Contains(t, "fioul"):
+		return energyFuel
+	case strings.Contains(t, "wood"), strings.Contains(t, "bois"), strings.Contains(t, "pellet"), strings.Contains(t, "granul"):
+		return energyWood
+	default:
+		return energyElectric
+	}
+}
+
+// containsWords reports whether the text contains one of the names as whole words, e.g. "pac" in
+// "PAC air/eau" but not in "capacité".
+func containsWords(text string, names []string) bool {
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	padded := " " + strings.Join(words, " ") + " "
+	for _, name := range names {
+		if strings.Contains(padded, " "+name+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// consumptionOf returns the consumption in kWh/m²/year, typed or deduced from the DPE letter, and
+// the source of the value. It returns 0 when both are unknown.
+func consumptionOf(consumption float64, rating string) (float64, string) {
+	if consumption > 0 {
+		return consumption, "consumption"
+	}
+	if c, exists := dpeConsumptions[rating]; exists {
+		return c, "dpe_rating"
+	}
+	return 0, ""
+}
+
+// energyCost returns the annual energy cost of a good for a consumption in kWh of primary energy
+// per m² per year. The heating is paid with its energy, the other uses with electricity.
+func energyCost(good Property, cfg EnergyConfig, energy string, consumption float64, source string) EnergyCost {
+	var (
+		primary = consumption * good.TotalLivingSpaceM2
+		heating = primary * cfg.heatingShare()
+		other   = primary - heating
+	)
+	if energy == energyElectric || energy == energyHeatPump {
+		heating /= electricityPrimaryFactor
+	}
+	other /= electricityPrimaryFactor
+	return EnergyCost{
+		Source:      source,
+		Consumption: consumption,
+		AnnualKWh:   heating + other,
+		HeatingCost: heating * cfg.tariff(energy),
+		OtherCost:   other * cfg.tariff(energyElectric),
+	}
+}
+
+// estimateEnergy estimates the annual energy cost of a good before and after its renovation. The
+// typed annual cost of the listing is kept when it exists, and scaled by the consumptions for the
+// renovation. The heating energy changes when the renovation installs a heat pump. It returns nil
+// when neither the consumption nor the DPE letter is known.
+func estimateEnergy(good Property, cfg EnergyConfig, renovation *RenovationPlan) *EnergyEstimate {
+	consumption, source := consumptionOf(good.EnergyConsumption, good.EnergyPerformanceRating)
+	if consumption == 0 {
+		return nil
+	}
+	var (
+		energy   = heatingEnergy(good.HeatingType)
+		before   = energyCost(good, cfg, energy, consumption, source)
+		modeled  = before.HeatingCost + before.OtherCost
+		estimate = &EnergyEstimate{HeatingEnergy: energy}
+	)
+
+	scale := 1.0
+	if good.EnergyConsumptionAnnualCost > 0 && modeled > 0 {
+		// split the typed cost of the listing between the uses
+		scale = good.EnergyConsumptionAnnualCost / modeled
+		before.Source = "listing"
+	}
+	estimate.Before = before.scaled(scale).rounded()
+
+	if consumption, source := consumptionOf(good.EnergyConsumptionAfterRenovation, good.EnergyPerformanceRatingAfterRenovation); consumption > 0 {
+		if renovation.installs(worksHeatPump) {
+			energy = energyHeatPump
+			estimate.HeatingEnergyAfter = energy
+		}
+		after := energyCost(good, cfg, energy, consumption, source).scaled(scale).rounded()
+		estimate.After = &after
+	}
+	return estimate
+}
+
+// annualCost returns the annual energy cost once the good is renovated, if a renovation is
+// described.
+func (e EnergyEstimate) annualCost() float64 {
+	if e.After != nil {
+		return e.After.AnnualCost
+	}
+	return e.Before.AnnualCost
+}
+
+func (c EnergyCost) scaled(factor float64) EnergyCost {
+	c.HeatingCost *= factor
+	c.OtherCost *= factor
+	return c
+}
+
+func (c EnergyCost) rounded() EnergyCost {
+	return EnergyCost{
+		Source:      c.Source,
+		Consumption: math.Round(c.Consumption),
+		AnnualKWh:   math.Round(c.AnnualKWh),
+		HeatingCost: math.Round(c.HeatingCost),
+		OtherCost:   math.Round(c.OtherCost),
+		AnnualCost:  math.Round(c.HeatingCost + c.OtherCost),
+	}
+}
@@ -0,0 +1,30 @@
+package immo
+
+import "testing"
+
+func TestHeatingEnergy(t *testing.T) {
+	tests := []struct {
+		heatingType string
+		want        string
+	}{
+		{heatingType: "PAC air/eau", want: energyHeatPump},
+		{heatingType: "Pompe à chaleur", want: energyHeatPump},
+		{heatingType: "pompe a chaleur réversible", want: energyHeatPump},
+		{heatingType: "Heat pump", want: energyHeatPump},
+		{heatingType: "Chaudière gaz", want: energyGas},
+		{heatingType: "Fioul", want: energyFuel},
+		{heatingType: "Poêle à granulés", want: energyWood},
+		{heatingType: "Radiateurs électriques", want: energyElectric},
+		// "pac" inside a word is not a heat pump
+		{heatingType: "Convecteurs grande capacité", want: energyElectric},
+		{heatingType: "Chauffage par le sol, espace chauffé", want: energyElectric},
+		// a pump alone is not a heat pump, e.g. the circulation pump of a gas boiler
+		{heatingType: "Gaz, pompe de circulation", want: energyGas},
+		{heatingType: "", want: energyElectric},
+	}
+	for _, tt := range tests {
+		if got := heatingEnergy(tt.heatingType); got != tt.want {
+			t.Errorf("heatingEnergy(%q) = %q, want %q", tt.heatingType, got, tt.want)
+		}
+	}
+}
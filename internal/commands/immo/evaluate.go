@@ -5,95 +5,307 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 )
 
-// note: we can improve this later
-// https://www.anil.org/outils/outils-de-calcul/frais-dacquisition-dits-frais-de-notaire/
-const notaryFeesRate = 0.08
-
 var evaluateCmd = &cobra.Command{
 	Use:   "evaluate",
 	Short: "Evaluate different scenarios of a real-estate purchase.",
-	Run:   runEvaluate,
+	RunE:  runEvaluate,
 }
 
-func runEvaluate(cmd *cobra.Command, args []string) {
-	cfg, err := loadConfig()
+var (
+	evaluateOutput  string
+	evaluateSummary bool
+	evaluateSort    string
+	evaluateDVF     []string
+)
+
+func init() {
+	evaluateCmd.Flags().StringVarP(&evaluateOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	evaluateCmd.Flags().BoolVar(&evaluateSummary, "summary", false, "Summarize the evaluation as a matrix of goods and mortgages")
+	evaluateCmd.Flags().StringVar(&evaluateSort, "sort", "", fmt.Sprintf("Sort the goods of the summary by a metric: %s", strings.Join(matrixMetricNames(), ", ")))
+	evaluateCmd.Flags().StringSliceVar(&evaluateDVF, "dvf", nil, "DVF files of the comparable sales used to estimate the fair value of the goods (default: comparables.files)")
+}
+
+func runEvaluate(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(evaluateOutput)
 	if err != nil {
-		println(err)
-		os.Exit(1)
+		return err
 	}
-	fmt.Printf("Found %d goods and %d mortgages to evaluate\n\n", len(cfg.Goods), len(cfg.EstimatedMortgages))
-
-	var cityStats = make(map[string]CityStats)
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Found %d goods and %d financing scenarios to evaluate\n", len(cfg.Goods), len(cfg.financingPlans()))
 	for _, city := range cfg.CityStats {
-		fmt.Printf("City %q (%s)\n", city.Name, city.ZipCode)
-		cityStats[city.ZipCode] = city
+		fmt.Fprintf(os.Stderr, "City %q (%s)\n", city.Name, city.ZipCode)
+	}
+	if len(evaluateDVF) > 0 {
+		cfg.Comparables.Files = evaluateDVF
+	}
+	if len(cfg.Comparables.Files) > 0 {
+		sales, err := loadSales(cfg.Comparables.Files)
+		if err != nil {
+			return err
+		}
+		cfg.fairValues = fairValuesOf(cfg.Goods, sales, cfg.Comparables.withDefaults())
 	}
 
-	for i, good := range cfg.Goods {
-		fmt.Printf("%d. Mortgages for %q (%.0fK)\n", i+1, good.Name, math.Round(good.Price/1000))
-		fmt.Println(good.OfferUrl)
-		fmt.Println("==========")
-		for j, mortgage := range cfg.EstimatedMortgages {
-			fmt.Printf("%d.%d. Mortgage %s %.0fK\n", i+1, j+1, mortgage.Bank, math.Round(mortgage.Amount/1000))
-			fmt.Println("----------")
+	report := evaluateAll(cfg)
+	if evaluateSummary {
+		return printMatrix(report, cfg.Family, format)
+	}
+	switch {
+	case format == outputText:
+		printReport(report)
+		return nil
+	case format.isDocument():
+		return writeDocument(os.Stdout, format, report)
+	default:
+		return writeTable(os.Stdout, format, report.table())
+	}
+}
 
-			result := evaluate(EvaluationContext{
-				Family:          cfg.Family,
-				CurrentProperty: cfg.CurrentProperty,
-				Mortgage:        mortgage,
-				CityStats:       cityStats,
-			}, good)
-			printResult(result)
+func printMatrix(report EvaluationReport, family FamilyContext, format outputFormat) error {
+	matrix, err := buildMatrix(report, family, evaluateSort)
+	if err != nil {
+		return err
+	}
+	if format.isDocument() {
+		return writeDocument(os.Stdout, format, matrix)
+	}
+	if err := writeTable(os.Stdout, format, matrix.table(format)); err != nil {
+		return err
+	}
+	if format != outputCSV {
+		fmt.Println()
+		fmt.Println(matrix.legend())
+	}
+	return nil
+}
+
+// evaluateAll evaluates every good with every financing scenario of the configuration.
+func evaluateAll(cfg ImmoConfig) EvaluationReport {
+	var report EvaluationReport
+	for _, good := range cfg.Goods {
+		for _, plan := range cfg.financingPlans() {
+			result := evaluate(newEvaluationContext(cfg, plan), good)
+			report.Evaluations = append(report.Evaluations, Evaluation{
+				Good:      good.Name,
+				Bank:      plan.Name,
+				Result:    result,
+				good:      good,
+				financing: result.financing,
+			})
 		}
 	}
+	return report
 }
 
-func loadConfig() (ImmoConfig, error) {
-	var (
-		rootConfigPath = os.Getenv("JIMI_CONFIG")
-		configPath     = rootConfigPath + "/immo.yaml"
-		config         ImmoConfig
-	)
+// newEvaluationContext creates the context to evaluate goods with the given financing plan.
+func newEvaluationContext(cfg ImmoConfig, financing FinancingPlan) EvaluationContext {
+	var cityStats = make(map[string]CityStats)
+	for _, city := range cfg.CityStats {
+		if previous, exists := cityStats[city.ZipCode]; exists && !city.replaces(previous) {
+			continue
+		}
+		cityStats[city.ZipCode] = city
+	}
+	return EvaluationContext{
+		Family:          cfg.Family,
+		CurrentProperty: cfg.CurrentProperty,
+		Financing:       financing,
+		CityStats:       cityStats,
+		Fees:            cfg.Fees,
+		Energy:          cfg.Energy,
+		AlertRules:      cfg.AlertRules,
+		FairValues:      cfg.fairValues,
+	}
+}
+
+// readConfig reads the configuration file immo.yaml located in the directory JIMI_CONFIG.
+func readConfig() (string, []byte, error) {
+	rootConfigPath := os.Getenv("JIMI_CONFIG")
 	if rootConfigPath == "" {
-		return config, errors.New("JIMI_CONFIG is not set")
+		return "", nil, errors.New("JIMI_CONFIG is not set")
 	}
+	configPath := rootConfigPath + "/immo.yaml"
 
-	println("Loading config from", configPath)
+	fmt.Fprintln(os.Stderr, "Loading config from", configPath)
 	bytes, err := os.ReadFile(configPath)
+	if err != nil {
+		return configPath, nil, err
+	}
+	return configPath, bytes, nil
+}
+
+// loadValidatedConfig loads the configuration, after checking that it is valid.
+func loadValidatedConfig() (ImmoConfig, error) {
+	var config ImmoConfig
+	path, bytes, err := readConfig()
 	if err != nil {
 		return config, err
 	}
+	if errs := validateConfig(bytes); len(errs) > 0 {
+		return config, fmt.Errorf("invalid configuration %s:\n%w", path, errs)
+	}
 	if err := yaml.Unmarshal(bytes, &config); err != nil {
 		return config, err
 	}
 	return config, nil
 }
 
+// printReport prints the report in a human-readable format, grouped by good.
+func printReport(report EvaluationReport) {
+	var (
+		goodIndex     int
+		mortgageIndex int
+		previousGood  string
+	)
+	for _, e := range report.Evaluations {
+		if goodIndex == 0 || e.Good != previousGood {
+			goodIndex++
+			mortgageIndex = 0
+			previousGood = e.Good
+			fmt.Println()
+			fmt.Printf("%d. Mortgages for %q (%.0fK)\n", goodIndex, e.good.Name, math.Round(e.good.Price/1000))
+			fmt.Println(e.good.OfferUrl)
+			fmt.Println("==========")
+		}
+		mortgageIndex++
+		fmt.Printf("%d.%d. Mortgage %s %.0fK\n", goodIndex, mortgageIndex, e.financing.Name, math.Round(e.financing.Amount()/1000))
+		fmt.Println("----------")
+		printResult(e.Result)
+	}
+}
+
 func printResult(result EvaluationResult) {
 	data, _ := yaml.Marshal(result)
-	println(string(data))
+	fmt.Println(string(data))
+}
+
+func formatDebtRatio(d *DebtRatio) string {
+	if d == nil {
+		return ""
+	}
+	return fmt.Sprintf("%.1f%%", d.Ratio*100)
+}
+
+// table returns the key metrics of the report, one row per good and mortgage.
+func (r EvaluationReport) table() table {
+	t := table{headers: []string{
+		"good",
+		"bank",
+		"total_purchase_cost",
+		"acquisition_fees",
+		"contribution",
+		"mortgage_amount",
+		"remaining_assets",
+		"monthly_mortgage_cost",
+		"monthly_housing_charges",
+		"monthly_expenses",
+		"monthly_expenses_diff",
+		"price_per_m2",
+		"avg_price_per_m2",
+		"debt_ratio",
+		"sell_contribution",
+		"sell_monthly_expenses",
+		"bridge_monthly_burden",
+		"alerts",
+	}}
+	for _, e := range r.Evaluations {
+		var (
+			purchase    = e.Result.NewPropertyPurchaseCost
+			operational = e.Result.NewPropertyOperationalCost
+			performance = e.Result.NewPropertyPerformance
+
+			sellContribution    string
+			sellMonthlyExpenses string
+			bridgeBurden        string
+		)
+		if e.Result.SellVsKeep != nil {
+			sellContribution = formatAmount(e.Result.SellVsKeep.Sell.Contribution)
+			sellMonthlyExpenses = formatAmount(e.Result.SellVsKeep.Sell.MonthlyExpenses)
+		}
+		if e.Result.BridgeLoan != nil {
+			bridgeBurden = formatAmount(e.Result.BridgeLoan.MonthlyDoubleBurden)
+		}
+		t.append(
+			e.Good,
+			e.Bank,
+			formatAmount(purchase.TotalPurchaseCost),
+			formatAmount(purchase.AcquisitionFees.Total),
+			formatAmount(purchase.Contribution),
+			formatAmount(purchase.MortgageAmount),
+			formatAmount(purchase.RemainingAssets),
+			formatAmount(operational.MonthlyMortgageCost),
+			formatAmount(operational.MonthlyHousingCharges),
+			formatAmount(operational.MonthlyExpenses),
+			operational.MonthlyExpensesDiff,
+			formatAmount(performance.PricePerM2),
+			formatAmount(performance.AveragePricePerM2),
+			formatDebtRatio(e.Result.DebtRatio),
+			sellContribution,
+			sellMonthlyExpenses,
+			bridgeBurden,
+			strings.Join(alertMessages(e.Result.Alerts), "; "),
+		)
+	}
+	return t
+}
+
+// totalPurchaseCost returns the total cost of the purchase of a good: price, fees and the works
+// and furniture needed to move in.
+func totalPurchaseCost(good Property, fees AcquisitionFees) float64 {
+	return good.Price + fees.Total + good.RenovationCost + good.FournitureCost
 }
 
 func evaluate(ctx EvaluationContext, good Property) EvaluationResult {
-	var alerts []string
+	var alerts []Alert
+
+	// the itemized renovation replaces the renovation cost and consumption of the listing, and its
+	// eco-PTZ finances the energy works when it is borrowed
+	renovation := planRenovation(good, ctx.Family.incomeBracket())
+	good = good.withRenovation(renovation)
+	ctx.Financing = ctx.Financing.withEcoPTZ(good, renovation)
 
 	// ----------
 	// Purchase: start
 	//
 	// Assume the agent fees are included in the price of the good.
-	purchaseCost := good.Price*(1+notaryFeesRate) + good.RenovationCost + good.FournitureCost
-
-	contribution := purchaseCost - ctx.Mortgage.Amount
+	acquisitionFees := computeAcquisitionFees(good, ctx.Fees)
+	if ctx.TransferTaxesFactor > 0 {
+		acquisitionFees = acquisitionFees.scaleTransferTaxes(ctx.TransferTaxesFactor)
+	}
+	financing := ctx.Financing
+	// the fees of the mortgage are paid at the signature
+	purchaseCost := totalPurchaseCost(good, acquisitionFees) + financing.fees()
+	contribution := purchaseCost - financing.Amount()
 
 	reminingAssets := ctx.Family.TotalAssets - contribution
 
+	for _, loan := range financing.Loans {
+		if loan.MonthlyCostMismatch() {
+			alerts = append(alerts, newAlert(alertMonthlyCostMismatch, severityWarning, "Mortgage monthly cost of %s differs from the computed one (%.0f != %.0f)",
+				loan.Bank,
+				loan.MonthlyCost,
+				loan.ComputedMonthlyCost()),
+			)
+		}
+		if len(loan.InsuranceCoverages) > 0 && loan.quotite() < 1 {
+			alerts = append(alerts, newAlert(alertPartialInsurance, severityInfo, "Insurance of %s covers less than 100%% of the capital (%.0f%%)",
+				loan.Bank,
+				loan.quotite()*100),
+			)
+		}
+	}
+
 	if contribution > ctx.Family.ContributionThreshold {
-		alerts = append(alerts, fmt.Sprintf("Contribution is above threshold (%.0fK > %.0fK)",
+		alerts = append(alerts, newAlert(alertContributionAboveLimit, severityError, "Contribution is above threshold (%.0fK > %.0fK)",
 			contribution/1000,
 			ctx.Family.ContributionThreshold/1000),
 		)
@@ -123,7 +335,7 @@ func evaluate(ctx EvaluationContext, good Property) EvaluationResult {
 				)
 			}
 		} else {
-			alerts = append(alerts, "City stats not found")
+			alerts = append(alerts, newAlert(alertCityStatsNotFound, severityInfo, "City stats not found"))
 		}
 	} else {
 		if stats, exists := ctx.CityStats[good.ZipCode]; exists {
@@ -145,26 +357,58 @@ func evaluate(ctx EvaluationContext, good Property) EvaluationResult {
 				)
 			}
 		} else {
-			alerts = append(alerts, "City stats not found")
+			alerts = append(alerts, newAlert(alertCityStatsNotFound, severityInfo, "City stats not found"))
+		}
+	}
+	if f := ctx.FairValues[good.Name]; f != nil {
+		performance.FairValue = f.withPrice(good.Price)
+		if f := performance.FairValue; f.Position == "above" {
+			alerts = append(alerts, newAlert(alertPriceAboveFairValue, severityWarning,
+				"Price is %.0f%% above the fair value of %d comparable sales. (%.0f > %.0f)",
+				(good.Price-f.High)/f.High*100,
+				f.Comparables,
+				good.Price,
+				f.High,
+			))
 		}
 	}
 
 	// ----------
 	// Renting: start
-	cp := ctx.CurrentProperty
-	// e.g. (1200-385)*(1-0.08) - 1378/12 - 920 = 635
-	rentingGain := (cp.MonthlyIncome-cp.MonthlyCharges)*(1-cp.GestionFeesRate) - cp.AnnualPropertyTax/12 - cp.MonthlyMortgage
-	renting := RentingPerformance{
-		NetMonthlyGain:    math.Round(rentingGain),
-		MonthlyMortgage:   cp.MonthlyMortgage,
-		SurfaceM2:         cp.SurfaceM2,
-		MonthlyIncome:     cp.MonthlyIncome,
-		MonthlyCharges:    cp.MonthlyCharges,
-		GestionFeesRate:   cp.GestionFeesRate,
-		GestionFees:       math.Round((cp.MonthlyIncome - cp.MonthlyCharges) * cp.GestionFeesRate),
-		AnnualPropertyTax: cp.AnnualPropertyTax,
-	}
-	additionalRentingIncome := cp.MonthlyIncome - renting.GestionFees
+	//
+	// A good bought as a rental investment is not our home: we keep living in the current
+	// property, so it is not rented, and the good is rented instead.
+	var (
+		cp                      = ctx.CurrentProperty
+		renting                 *RentingPerformance
+		additionalRentingIncome float64
+		investment              = computeInvestment(ctx, good, acquisitionFees, purchaseCost)
+	)
+	if investment == nil {
+		// e.g. (1200-385)*(1-0.08) - 1378/12 - 920 = 635
+		rentingGain := (cp.MonthlyIncome-cp.MonthlyCharges)*(1-cp.GestionFeesRate) - cp.AnnualPropertyTax/12 - cp.MonthlyMortgage
+		renting = &RentingPerformance{
+			NetMonthlyGain:    math.Round(rentingGain),
+			MonthlyMortgage:   cp.MonthlyMortgage,
+			SurfaceM2:         cp.SurfaceM2,
+			MonthlyIncome:     cp.MonthlyIncome,
+			MonthlyCharges:    cp.MonthlyCharges,
+			GestionFeesRate:   cp.GestionFeesRate,
+			GestionFees:       math.Round((cp.MonthlyIncome - cp.MonthlyCharges) * cp.GestionFeesRate),
+			AnnualPropertyTax: cp.AnnualPropertyTax,
+		}
+		renting.NetMonthlyGainAfterTax = renting.NetMonthlyGain
+		if cp.MonthlyIncome > 0 {
+			tax := currentPropertyTax(cp, ctx.Family.MarginalTaxRate)
+			if !tax.Eligible {
+				alerts = append(alerts, newAlert(alertRentsAboveRegimeCeiling, severityWarning, "Rents of the current property are above the %s ceiling", tax.Regime))
+			}
+			renting.NetMonthlyGainAfterTax = math.Round(rentingGain - tax.Total/12)
+			tax = tax.rounded()
+			renting.Tax = &tax
+		}
+		additionalRentingIncome = cp.MonthlyIncome - renting.GestionFees
+	}
 	// Renting: end
 	// ----------
 
@@ -176,32 +420,105 @@ func evaluate(ctx EvaluationContext, good Property) EvaluationResult {
 		monthlyTaxCost        = good.AnnualPropertyTax / 12
 		monthlyHousingCharges = monthlyTaxCost
 	)
-	if len(good.EnergyPerformanceRatingAfterRenovation) > 0 {
-		// renovation included
-		monthlyEnergyConsumptionCost := good.EnergyConsumptionAnnualCost * (good.EnergyConsumptionAfterRenovation / good.EnergyConsumption) / 12
-		monthlyHousingCharges += monthlyEnergyConsumptionCost
-	} else {
-		// if we don't know the DPE, we project our current concumption
-		// If the good is bigger than the current home, the monthly housing charges will increase proportionally.
-		monthlyHousingCharges += ctx.CurrentProperty.MonthlyCharges * (good.TotalLivingSpaceM2 / ctx.CurrentProperty.SurfaceM2)
+	energy := estimateEnergy(good, ctx.Energy, renovation)
+	if investment != nil {
+		// the energy is paid by the tenant, the charges, taxes and insurances by the owner
+		monthlyHousingCharges = investment.AnnualExpenses / 12
+	} else if energy != nil {
+		// renovation included; the DPE letter is required, so the energy of a configured good is
+		// always estimated
+		monthlyHousingCharges += energy.annualCost() / 12
 	}
-	monthlyExpenses := ctx.Family.MonthlyExpenses - additionalRentingIncome + monthlyHousingCharges + ctx.Mortgage.MonthlyCost
+	monthlyMortgagePayment := financing.MonthlyPayment()
+	monthlyExpenses := ctx.Family.MonthlyExpenses - additionalRentingIncome + monthlyHousingCharges + monthlyMortgagePayment
 
-	// Remove fees that we don't need anymore
-	if good.HasGarage {
-		monthlyExpenses -= ctx.Family.MonthlyParkingFee
+	if investment != nil {
+		monthlyExpenses -= investment.AnnualRents / 12
+	} else {
+		// Remove fees that we don't need anymore
+		if good.HasGarage {
+			monthlyExpenses -= ctx.Family.MonthlyParkingFee
+		}
+		monthlyExpenses -= ctx.Family.MonthlySecondaryResidenceCost
+		monthlyExpenses -= ctx.Family.MonthlyElectricityCost
 	}
-	monthlyExpenses -= ctx.Family.MonthlySecondaryResidenceCost
-	monthlyExpenses -= ctx.Family.MonthlyElectricityCost
 
 	monthlyExpensesDiff := fmt.Sprintf("%.0f (%.0f%%)",
 		monthlyExpenses-ctx.Family.MonthlyExpenses,
 		(monthlyExpenses-ctx.Family.MonthlyExpenses)/ctx.Family.MonthlyExpenses*100,
 	)
-	annualHousingCost := (monthlyHousingCharges+ctx.Mortgage.MonthlyCost)*12 + good.AnnualPropertyTax
+	annualHousingCost := (monthlyHousingCharges+monthlyMortgagePayment)*12 + good.AnnualPropertyTax
+	if investment != nil {
+		// the property tax is already in the expenses of the investment
+		annualHousingCost -= good.AnnualPropertyTax
+	}
 	// Operational costs: end
 	// ----------
 
+	// ----------
+	// Debt ratio: start
+	var (
+		debtRatio           *DebtRatio
+		monthlyLoanPayments = monthlyMortgagePayment + financing.Insurance() + cp.MonthlyMortgage
+	)
+	if ctx.Family.MonthlyNetIncome() > 0 {
+		rentalIncome := cp.MonthlyIncome
+		if investment != nil {
+			rentalIncome = good.Investment.MonthlyRent
+		}
+		ratio := computeDebtRatio(ctx.Family, rentalIncome, monthlyLoanPayments)
+		if ratio.Exceeded() {
+			alerts = append(alerts, newAlert(alertDebtRatioExceeded, severityError, "Debt ratio is above the limit (%.1f%% > %.0f%%)",
+				ratio.Ratio*100,
+				ratio.Limit*100),
+			)
+		}
+		ratio = ratio.rounded()
+		debtRatio = &ratio
+	}
+	// Debt ratio: end
+	// ----------
+
+	// ----------
+	// Sell vs keep: start
+	keep := ScenarioSummary{
+		Contribution:    math.Round(contribution),
+		RemainingAssets: math.Round(reminingAssets),
+		MonthlyExpenses: math.Round(monthlyExpenses),
+	}
+	if debtRatio != nil {
+		keep.DebtRatio = debtRatio.Ratio
+	}
+	//
+	// Selling the current property, and bridging its sale, only apply when the good replaces it.
+	var (
+		sellVsKeep *SellVsKeep
+		bridgeLoan *BridgeLoanResult
+	)
+	if investment == nil {
+		sellVsKeep = compareSellVsKeep(ctx, keep, purchaseCost, additionalRentingIncome, monthlyLoanPayments)
+	}
+	// Sell vs keep: end
+	// ----------
+
+	// ----------
+	// Bridge loan: start
+	if investment == nil {
+		var bridgeAlerts []Alert
+		bridgeLoan, bridgeAlerts = computeBridgeLoan(ctx, purchaseCost, monthlyLoanPayments)
+		alerts = append(alerts, bridgeAlerts...)
+	}
+	// Bridge loan: end
+	// ----------
+
+	restrictions, restrictionAlerts := rentalRestrictions(cp, good)
+	alerts = append(alerts, restrictionAlerts...)
+
+	var financingLines []FinancingLine
+	if len(financing.Loans) > 1 {
+		financingLines = financing.lines()
+	}
+
 	// ----------
 	// Cost summary: start
 	costSummary := CostSummary{
@@ -210,27 +527,44 @@ func evaluate(ctx EvaluationContext, good Property) EvaluationResult {
 	// Cost summary: end
 	// ----------
 
-	return EvaluationResult{
+	result := EvaluationResult{
 		NewPropertyPurchaseCost: PurchaseCost{
-			MortgageAmount:        math.Round(ctx.Mortgage.Amount),
+			MortgageAmount:        math.Round(financing.Amount()),
 			Contribution:          math.Round(contribution),
 			TotalPurchaseCost:     math.Round(purchaseCost),
+			AcquisitionFees:       acquisitionFees.rounded(),
+			MortgageFees:          math.Round(financing.fees()),
 			RemainingAssets:       math.Round(reminingAssets),
 			RenovationCost:        math.Round(good.RenovationCost),
 			RenovationDescription: good.RenovationDescription,
 			FournitureCost:        math.Round(good.FournitureCost),
 		},
 		NewPropertyOperationalCost: OperationalCost{
-			MonthlyMortgageCost:    math.Round(ctx.Mortgage.MonthlyCost + ctx.Mortgage.Insurance),
+			MonthlyMortgageCost:    math.Round(monthlyMortgagePayment + financing.Insurance()),
+			MortgageTotalInterest:  math.Round(financing.TotalInterest()),
+			MortgageTotalInsurance: math.Round(financing.TotalInsurance()),
+			TotalCreditCost:        math.Round(financing.TotalCreditCost()),
+			TAEG:                   math.Round(financing.TAEG()*10000) / 10000,
 			MonthlyHousingCharges:  math.Round(monthlyHousingCharges),
 			MonthlyExpenses:        math.Round(monthlyExpenses),
 			MonthlyExpensesDiff:    monthlyExpensesDiff,
 			AnnualPropertyTax:      math.Round(good.AnnualPropertyTax),
 			TotalAnnualHousingCost: math.Round(annualHousingCost),
+			Energy:                 energy,
+			FinancingLines:         financingLines,
 		},
 		NewPropertyPerformance: performance,
 		Renting:                renting,
+		DebtRatio:              debtRatio,
+		SellVsKeep:             sellVsKeep,
+		BridgeLoan:             bridgeLoan,
+		Investment:             investment,
+		RentalRestrictions:     restrictions,
+		Renovation:             renovation,
 		Alerts:                 alerts,
 		CostSummary:            costSummary,
+		financing:              financing,
 	}
+	result.Alerts = append(result.Alerts, ruleAlerts(ctx.AlertRules, good, result)...)
+	return result
 }
@@ -0,0 +1,676 @@
+package immo
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file implements the small expression language of the alert rules, e.g.
+//
+//	good.energy_performance_rating in ["F", "G"] and result.remaining_assets < 30000
+//
+// An expression combines paths, numbers, strings, booleans and lists with the arithmetic operators
+// + - * /, the comparisons == != < <= > >=, the membership tests in and not in, and the logical
+// operators and, or and not. Paths are the YAML names of the fields of the good and of the result
+// of the evaluation, separated by dots. A field of a section can be named without its section, e.g.
+// result.remaining_assets for result.new_property_purchase.remaining_assets: when several sections
+// have the field, the purchase section wins, e.g. result.contribution is the contribution to the
+// purchase and not the one of the bridge loan.
+
+// canonicalSection is the section of the result searched first for a field named without its
+// section.
+const canonicalSection = "new_property_purchase"
+
+// evaluationEnv returns the environment of the expressions for a good and the result of its
+// evaluation.
+func evaluationEnv(good Property, result EvaluationResult) map[string]any {
+	return map[string]any{"good": valueMap(good), "result": valueMap(result)}
+}
+
+// expr is a parsed expression.
+type expr interface {
+	eval(env map[string]any) (any, error)
+}
+
+type (
+	literalExpr struct{ value any }
+	pathExpr    struct{ path string }
+	listExpr    struct{ items []expr }
+	unaryExpr   struct {
+		op      string
+		operand expr
+	}
+	binaryExpr struct {
+		op          string
+		left, right expr
+	}
+)
+
+// token is a lexical token of an expression.
+type token struct {
+	kind  tokenKind
+	text  string
+	value any // value of number and string tokens
+	pos   int
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent // paths and keywords
+	tokenOperator
+)
+
+// parseExpr parses an expression. It returns an error describing the first syntax error.
+func parseExpr(source string) (expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+	}
+	return e, nil
+}
+
+func tokenize(source string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(source)
+	)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				i++
+			}
+			text := string(runes[start:i])
+			f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start+1)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: f, pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			i++
+			for i < len(runes) && runes[i] != r {
+				i++
+			}
+			if i == len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start+1)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: string(runes[start:i]), value: string(runes[start+1 : i-1]), pos: start})
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			start := i
+			if i+1 < len(runes) {
+				if two := string(runes[i : i+2]); two == "==" || two == "!=" || two == "<=" || two == ">=" {
+					tokens = append(tokens, token{kind: tokenOperator, text: two, pos: start})
+					i += 2
+					continue
+				}
+			}
+			if !strings.ContainsRune("<>+-*/()[],", r) {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, start+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: string(r), pos: start})
+			i++
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, text: "end of expression", pos: len(runes)}), nil
+}
+
+type exprParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *exprParser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token if it is the given operator or keyword.
+func (p *exprParser) accept(text string) bool {
+	if t := p.peek(); (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(text string) error {
+	if !p.accept(text) {
+		t := p.peek()
+		return fmt.Errorf("expected %q at position %d, got %q", text, t.pos+1, t.text)
+	}
+	return nil
+}
+
+func (p *exprParser) parseOr() (expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (expr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (expr, error) {
+	if p.accept("not") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: "not", operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (expr, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(op) {
+			right, err := p.parseSum()
+			if err != nil {
+				return nil, err
+			}
+			return binaryExpr{op: op, left: left, right: right}, nil
+		}
+	}
+	if t := p.peek(); t.kind == tokenIdent && t.text == "not" {
+		p.next()
+		if err := p.expect("in"); err != nil {
+			return nil, err
+		}
+		right, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: "not", operand: binaryExpr{op: "in", left: left, right: right}}, nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseSum() (expr, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.accept("+") && !p.accept("-") {
+			return left, nil
+		}
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseProduct() (expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := p.peek().text
+		if !p.accept("*") && !p.accept("/") {
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = binaryExpr{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (expr, error) {
+	if p.accept("-") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return unaryExpr{op: "-", operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (expr, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return literalExpr{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return literalExpr{value: true}, nil
+		case "false":
+			return literalExpr{value: false}, nil
+		case "null":
+			return literalExpr{value: nil}, nil
+		case "and", "or", "not", "in":
+			return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+		}
+		return pathExpr{path: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			e, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			return e, p.expect(")")
+		case "[":
+			var list listExpr
+			for !p.accept("]") {
+				if len(list.items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.parseOr()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos+1)
+}
+
+// exprPaths returns the paths referenced by the expression.
+func exprPaths(e expr) []string {
+	switch e := e.(type) {
+	case pathExpr:
+		return []string{e.path}
+	case listExpr:
+		var paths []string
+		for _, item := range e.items {
+			paths = append(paths, exprPaths(item)...)
+		}
+		return paths
+	case unaryExpr:
+		return exprPaths(e.operand)
+	case binaryExpr:
+		return append(exprPaths(e.left), exprPaths(e.right)...)
+	default:
+		return nil
+	}
+}
+
+func (e literalExpr) eval(map[string]any) (any, error) {
+	return e.value, nil
+}
+
+func (e pathExpr) eval(env map[string]any) (any, error) {
+	return lookupPath(env, e.path)
+}
+
+func (e listExpr) eval(env map[string]any) (any, error) {
+	values := make([]any, len(e.items))
+	for i, item := range e.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (e unaryExpr) eval(env map[string]any) (any, error) {
+	v, err := e.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch e.op {
+	case "not":
+		return !truthy(v), nil
+	default:
+		f, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("cannot negate %s", describeValue(v))
+		}
+		return -f, nil
+	}
+}
+
+func (e binaryExpr) eval(env map[string]any) (any, error) {
+	left, err := e.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	// short-circuit the logical operators
+	switch e.op {
+	case "and":
+		if !truthy(left) {
+			return false, nil
+		}
+	case "or":
+		if truthy(left) {
+			return true, nil
+		}
+	}
+	right, err := e.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.op {
+	case "and", "or":
+		return truthy(right), nil
+	case "==":
+		return equalValues(left, right), nil
+	case "!=":
+		return !equalValues(left, right), nil
+	case "in":
+		list, ok := right.([]any)
+		if !ok {
+			return nil, fmt.Errorf("in expects a list, got %s", describeValue(right))
+		}
+		for _, item := range list {
+			if equalValues(left, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	// a missing field cannot be compared nor computed: the rule does not apply
+	if left == nil || right == nil {
+		if strings.ContainsAny(e.op, "<>") {
+			return false, nil
+		}
+		return nil, nil
+	}
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			break
+		}
+		switch e.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		case "/":
+			if r == 0 {
+				return math.Inf(1), nil
+			}
+			return l / r, nil
+		}
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			break
+		}
+		switch e.op {
+		case "<":
+			return l < r, nil
+		case "<=":
+			return l <= r, nil
+		case ">":
+			return l > r, nil
+		case ">=":
+			return l >= r, nil
+		case "+":
+			return l + r, nil
+		}
+	}
+	return nil, fmt.Errorf("cannot apply %s to %s and %s", e.op, describeValue(left), describeValue(right))
+}
+
+// lookupPath returns the value at the dotted path in the environment, or nil when it does not
+// exist. A key missing from a mapping is searched in its sections: it is an error when several
+// sections have it, unless one of them is the canonical section.
+func lookupPath(env map[string]any, path string) (any, error) {
+	var (
+		current any = env
+		prefix  []string
+	)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		value, exists := m[key]
+		if !exists {
+			var candidates []string
+			for name, section := range m {
+				if s, ok := section.(map[string]any); ok {
+					if v, exists := s[key]; exists {
+						value = v
+						candidates = append(candidates, strings.Join(append(slices.Clone(prefix), name, key), "."))
+					}
+				}
+			}
+			if s, ok := m[canonicalSection].(map[string]any); ok && len(candidates) > 1 {
+				if v, exists := s[key]; exists {
+					value, candidates = v, nil
+				}
+			}
+			if len(candidates) > 1 {
+				sort.Strings(candidates)
+				return nil, fmt.Errorf("ambiguous field %s, use one of %s", path, strings.Join(candidates, ", "))
+			}
+		}
+		prefix = append(prefix, key)
+		current = value
+	}
+	return current, nil
+}
+
+func truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	case []any:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func equalValues(a, b any) bool {
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case float64, string, bool:
+		return a == b
+	default:
+		return false
+	}
+}
+
+func describeValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case float64:
+		return fmt.Sprintf("number %g", v)
+	case bool:
+		return fmt.Sprintf("boolean %t", v)
+	case []any:
+		return "a list"
+	default:
+		return "a mapping"
+	}
+}
+
+// formatValue formats a value for a message: amounts without decimals, rates with their
+// significant digits.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "?"
+	case float64:
+		if v == math.Trunc(v) {
+			return strconv.FormatFloat(v, 'f', 0, 64)
+		}
+		return strconv.FormatFloat(v, 'g', 4, 64)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatValue(item)
+		}
+		return strings.Join(items, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// valueMap returns the fields of a struct keyed by their YAML names, with all the numbers as
+// float64, so that the expressions see the same fields as the configuration and the output. The
+// fields omitted when empty are kept with their zero value, e.g. good.has_garage is false and not
+// missing when the garage is not set. Only the nil sections are missing.
+func valueMap(v any) map[string]any {
+	m, _ := reflectValue(reflect.ValueOf(v)).(map[string]any)
+	return m
+}
+
+func reflectValue(v reflect.Value) any {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return reflectValue(v.Elem())
+	case reflect.Struct:
+		var (
+			m = make(map[string]any)
+			t = v.Type()
+		)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			switch name {
+			case "-":
+				continue
+			case "":
+				name = strings.ToLower(field.Name)
+			}
+			m[name] = reflectValue(v.Field(i))
+		}
+		return m
+	case reflect.Map:
+		m := make(map[string]any, v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			m[fmt.Sprint(iter.Key().Interface())] = reflectValue(iter.Value())
+		}
+		return m
+	case reflect.Slice, reflect.Array:
+		values := make([]any, v.Len())
+		for i := range values {
+			values[i] = reflectValue(v.Index(i))
+		}
+		return values
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	default:
+		return nil
+	}
+}
+
+// checkPathRoots returns an error message for each path which does not start with one of the
+// roots of the evaluation environment, good and result.
+func checkPathRoots(paths []string) []string {
+	var messages []string
+	for _, path := range paths {
+		root, _, _ := strings.Cut(path, ".")
+		if root != "good" && root != "result" {
+			messages = append(messages, fmt.Sprintf("unknown field %q, expected a path starting with good or result", path))
+		}
+	}
+	return messages
+}
@@ -0,0 +1,58 @@
+package immo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLookupPath(t *testing.T) {
+	env := evaluationEnv(Property{Price: 400000}, EvaluationResult{
+		NewPropertyPurchaseCost: PurchaseCost{Contribution: 50000},
+		BridgeLoan:              &BridgeLoanResult{Contribution: 80000, DebtRatio: 0.4},
+	})
+	tests := []struct {
+		path string
+		want any
+	}{
+		{path: "good.price", want: 400000.0},
+		// the purchase section wins over the bridge loan
+		{path: "result.contribution", want: 50000.0},
+		{path: "result.bridge_loan.contribution", want: 80000.0},
+		// the fields omitted when empty are kept with their zero value
+		{path: "good.has_garage", want: false},
+		{path: "good.zip_code", want: ""},
+		// the nil sections are missing
+		{path: "result.sell_vs_keep.net_proceeds", want: nil},
+	}
+	for _, tt := range tests {
+		if got, err := lookupPath(env, tt.path); err != nil || got != tt.want {
+			t.Errorf("lookupPath(%s) = %v, %v, want %v", tt.path, got, err, tt.want)
+		}
+	}
+
+	// a field of a single section is found without it
+	if got, err := lookupPath(env, "result.repayment"); err != nil || got != "" {
+		t.Errorf("lookupPath(result.repayment) = %v, %v, want the field of the bridge loan", got, err)
+	}
+}
+
+func TestLookupPathAmbiguous(t *testing.T) {
+	env := map[string]any{"result": map[string]any{
+		"a": map[string]any{"x": 1.0},
+		"b": map[string]any{"x": 2.0},
+	}}
+	if _, err := lookupPath(env, "result.x"); err == nil || !strings.Contains(err.Error(), "result.a.x, result.b.x") {
+		t.Errorf("lookupPath(result.x) error = %v, want ambiguous", err)
+	}
+}
+
+func TestEvalMissingBoolean(t *testing.T) {
+	e, err := parseExpr("good.has_garage == false and not good.has_garden")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := e.eval(evaluationEnv(Property{}, EvaluationResult{}))
+	if err != nil || got != true {
+		t.Errorf("eval() = %v, %v, want true for a good without garage nor garden", got, err)
+	}
+}
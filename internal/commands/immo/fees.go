@@ -0,0 +1,165 @@
+package immo
+
+import "math"
+
+// Acquisition fees (frais d'acquisition, dits "frais de notaire") of a property in France.
+//
+// See https://www.anil.org/outils/outils-de-calcul/frais-dacquisition-dits-frais-de-notaire/
+const (
+	// defaultDepartmentalRate is the departmental part of the DMTO. Since April 2025, départements
+	// can raise it up to 5%, use FeesConfig.DepartmentalRates to override it.
+	defaultDepartmentalRate = 0.045
+	// communalRate is the communal part of the DMTO.
+	communalRate = 0.012
+	// stateCollectionRate is the fee collected by the state on the departmental part (frais
+	// d'assiette et de recouvrement).
+	stateCollectionRate = 0.0237
+	// newBuildLandRegistryRate is the taxe de publicité foncière paid for new builds (neuf or VEFA)
+	// instead of the DMTO.
+	newBuildLandRegistryRate = 0.00715
+	// securityContributionRate is the contribution de sécurité immobilière, with a minimum of
+	// securityContributionMin euros.
+	securityContributionRate = 0.001
+	securityContributionMin  = 15
+	// emolumentsVATRate is the VAT applied to the emoluments of the notary.
+	emolumentsVATRate = 0.20
+	// defaultDebours is the flat amount of disbursements and formalities paid by the notary
+	// (cadastre, town hall, etc.).
+	defaultDebours = 1200
+)
+
+// emolumentBracket is a bracket of the regulated emoluments of the notary for a sale.
+type emolumentBracket struct {
+	upTo float64
+	rate float64
+}
+
+// emolumentBrackets is the tiered scale of the emoluments of the notary (without VAT), applicable
+// since 2021.
+var emolumentBrackets = []emolumentBracket{
+	{upTo: 6500, rate: 0.03870},
+	{upTo: 17000, rate: 0.01596},
+	{upTo: 60000, rate: 0.01064},
+	{upTo: math.Inf(1), rate: 0.00799},
+}
+
+// FeesConfig configures the computation of the acquisition fees.
+type FeesConfig struct {
+	// DefaultDepartmentalRate overrides the departmental part of the DMTO for all départements.
+	DefaultDepartmentalRate float64 `yaml:"default_departmental_rate"`
+
+	// DepartmentalRates overrides the departmental part of the DMTO of some départements, keyed by
+	// the code of the département (e.g. "92", "2A", "974").
+	DepartmentalRates map[string]float64 `yaml:"departmental_rates"`
+
+	// Debours is the flat amount of disbursements and formalities paid by the notary.
+	Debours float64 `yaml:"debours"`
+}
+
+func (c FeesConfig) departmentalRate(department string) float64 {
+	if rate, exists := c.DepartmentalRates[department]; exists {
+		return rate
+	}
+	if c.DefaultDepartmentalRate > 0 {
+		return c.DefaultDepartmentalRate
+	}
+	return defaultDepartmentalRate
+}
+
+func (c FeesConfig) debours() float64 {
+	if c.Debours > 0 {
+		return c.Debours
+	}
+	return defaultDebours
+}
+
+// departmentOf returns the code of the département of a zip code.
+func departmentOf(zipCode string) string {
+	if len(zipCode) < 3 {
+		return zipCode
+	}
+	switch {
+	case zipCode[:2] == "97" || zipCode[:2] == "98":
+		// overseas départements have 3 digits
+		return zipCode[:3]
+	case zipCode[:2] == "20":
+		// Corse-du-Sud (200xx-201xx) and Haute-Corse (202xx-206xx)
+		if zipCode[2] < '2' {
+			return "2A"
+		}
+		return "2B"
+	default:
+		return zipCode[:2]
+	}
+}
+
+// transferTaxesRate returns the rate of the transfer taxes paid for a good.
+func transferTaxesRate(good Property, cfg FeesConfig) float64 {
+	if good.NewBuild {
+		return newBuildLandRegistryRate
+	}
+	departmental := cfg.departmentalRate(departmentOf(good.ZipCode))
+	return departmental + communalRate + departmental*stateCollectionRate
+}
+
+// notaryEmoluments returns the regulated emoluments of the notary for a sale, without VAT.
+func notaryEmoluments(base float64) float64 {
+	var (
+		total float64
+		lower float64
+	)
+	for _, b := range emolumentBrackets {
+		if base <= lower {
+			break
+		}
+		total += (math.Min(base, b.upTo) - lower) * b.rate
+		lower = b.upTo
+	}
+	return total
+}
+
+// computeAcquisitionFees returns the detail of the acquisition fees of a good. The furniture sold
+// with the good (mobilier) is not subject to the transfer taxes, so it is removed from the taxable
+// base.
+func computeAcquisitionFees(good Property, cfg FeesConfig) AcquisitionFees {
+	var (
+		base          = math.Max(good.Price-good.FurnitureValue, 0)
+		rate          = transferTaxesRate(good, cfg)
+		transferTaxes = base * rate
+		emoluments    = notaryEmoluments(base) * (1 + emolumentsVATRate)
+		security      = math.Max(base*securityContributionRate, securityContributionMin)
+		debours       = cfg.debours()
+	)
+	return AcquisitionFees{
+		TaxableBase:          base,
+		TransferTaxesRate:    rate,
+		TransferTaxes:        transferTaxes,
+		NotaryEmoluments:     emoluments,
+		SecurityContribution: security,
+		Debours:              debours,
+		Total:                transferTaxes + emoluments + security + debours,
+	}
+}
+
+// scaleTransferTaxes returns a copy of the fees with the rate of the transfer taxes scaled by the
+// factor, whether it is the DMTO of an existing good or the reduced rate of a new build.
+func (f AcquisitionFees) scaleTransferTaxes(factor float64) AcquisitionFees {
+	transferTaxes := f.TransferTaxes * factor
+	f.Total += transferTaxes - f.TransferTaxes
+	f.TransferTaxesRate *= factor
+	f.TransferTaxes = transferTaxes
+	return f
+}
+
+// rounded returns a copy of the fees with amounts rounded to the euro, for display.
+func (f AcquisitionFees) rounded() AcquisitionFees {
+	return AcquisitionFees{
+		TaxableBase:          math.Round(f.TaxableBase),
+		TransferTaxesRate:    math.Round(f.TransferTaxesRate*1e5) / 1e5,
+		TransferTaxes:        math.Round(f.TransferTaxes),
+		NotaryEmoluments:     math.Round(f.NotaryEmoluments),
+		SecurityContribution: math.Round(f.SecurityContribution),
+		Debours:              math.Round(f.Debours),
+		Total:                math.Round(f.Total),
+	}
+}
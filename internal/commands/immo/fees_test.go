@@ -0,0 +1,154 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNotaryEmoluments(t *testing.T) {
+	// reference values of the scale of the emoluments (arrêté du 28 février 2020), without VAT
+	tests := []struct {
+		base float64
+		want float64
+	}{
+		{base: 0, want: 0},
+		{base: 5000, want: 193.50},
+		{base: 6500, want: 251.55},
+		{base: 17000, want: 419.13},
+		{base: 60000, want: 876.65},
+		{base: 200000, want: 1995.25},
+		{base: 500000, want: 4392.25},
+	}
+	for _, tt := range tests {
+		if got := notaryEmoluments(tt.base); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("notaryEmoluments(%.0f) = %.2f, want %.2f", tt.base, got, tt.want)
+		}
+	}
+}
+
+func TestDepartmentOf(t *testing.T) {
+	tests := map[string]string{
+		"92160": "92",
+		"01000": "01",
+		"20000": "2A",
+		"20200": "2B",
+		"97400": "974",
+		"97110": "971",
+	}
+	for zipCode, want := range tests {
+		if got := departmentOf(zipCode); got != want {
+			t.Errorf("departmentOf(%q) = %q, want %q", zipCode, got, want)
+		}
+	}
+}
+
+func TestTransferTaxesRate(t *testing.T) {
+	cfg := FeesConfig{DepartmentalRates: map[string]float64{"75": 0.05}}
+	tests := []struct {
+		name string
+		good Property
+		want float64
+	}{
+		{name: "default rate", good: Property{ZipCode: "92160"}, want: 0.0580665},
+		{name: "raised rate", good: Property{ZipCode: "75015"}, want: 0.063185},
+		{name: "new build", good: Property{ZipCode: "75015", NewBuild: true}, want: 0.00715},
+	}
+	for _, tt := range tests {
+		if got := transferTaxesRate(tt.good, cfg); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: transferTaxesRate() = %.7f, want %.7f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestComputeAcquisitionFees(t *testing.T) {
+	tests := []struct {
+		name string
+		good Property
+		want AcquisitionFees
+	}{
+		{
+			name: "existing good",
+			good: Property{Price: 300000, ZipCode: "92160"},
+			want: AcquisitionFees{
+				TaxableBase:          300000,
+				TransferTaxesRate:    0.05807,
+				TransferTaxes:        17420,
+				NotaryEmoluments:     3353,
+				SecurityContribution: 300,
+				Debours:              1200,
+				Total:                22273,
+			},
+		},
+		{
+			name: "furniture out of the base",
+			good: Property{Price: 310000, FurnitureValue: 10000, ZipCode: "92160"},
+			want: AcquisitionFees{
+				TaxableBase:          300000,
+				TransferTaxesRate:    0.05807,
+				TransferTaxes:        17420,
+				NotaryEmoluments:     3353,
+				SecurityContribution: 300,
+				Debours:              1200,
+				Total:                22273,
+			},
+		},
+		{
+			name: "new build",
+			good: Property{Price: 300000, ZipCode: "92160", NewBuild: true},
+			want: AcquisitionFees{
+				TaxableBase:          300000,
+				TransferTaxesRate:    0.00715,
+				TransferTaxes:        2145,
+				NotaryEmoluments:     3353,
+				SecurityContribution: 300,
+				Debours:              1200,
+				Total:                6998,
+			},
+		},
+		{
+			name: "minimum security contribution",
+			good: Property{Price: 10000, ZipCode: "92160"},
+			want: AcquisitionFees{
+				TaxableBase:          10000,
+				TransferTaxesRate:    0.05807,
+				TransferTaxes:        581,
+				NotaryEmoluments:     369,
+				SecurityContribution: 15,
+				Debours:              1200,
+				Total:                2165,
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := computeAcquisitionFees(tt.good, FeesConfig{}).rounded(); got != tt.want {
+			t.Errorf("%s: computeAcquisitionFees() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScaleTransferTaxes(t *testing.T) {
+	tests := []struct {
+		name string
+		good Property
+		rate float64
+	}{
+		{name: "existing good", good: Property{Price: 300000, ZipCode: "92160"}, rate: 0.0580665},
+		{name: "new build", good: Property{Price: 300000, ZipCode: "92160", NewBuild: true}, rate: 0.00715},
+	}
+	for _, tt := range tests {
+		var (
+			fees   = computeAcquisitionFees(tt.good, FeesConfig{})
+			scaled = fees.scaleTransferTaxes(1.1)
+		)
+		if got, want := scaled.TransferTaxesRate, tt.rate*1.1; math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: rate = %.7f, want %.7f", tt.name, got, want)
+		}
+		if got, want := scaled.TransferTaxes, 300000*tt.rate*1.1; math.Abs(got-want) > 1e-6 {
+			t.Errorf("%s: transfer taxes = %.2f, want %.2f", tt.name, got, want)
+		}
+		// only the transfer taxes change
+		if got, want := scaled.Total-fees.Total, 300000*tt.rate*0.1; math.Abs(got-want) > 1e-6 {
+			t.Errorf("%s: total increase = %.2f, want %.2f", tt.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package immo
+
+import "math"
+
+// Kinds of loan lines in a financing plan.
+const (
+	loanKindMain           = "main"
+	loanKindPTZ            = "ptz"             // prêt à taux zéro
+	loanKindActionLogement = "action_logement" // employer loan
+	loanKindFamily         = "family"
+	loanKindEcoPTZ         = "eco_ptz" // zero-rate loan for the energy works
+	loanKindOther          = "other"
+)
+
+var loanKinds = []string{loanKindMain, loanKindPTZ, loanKindActionLogement, loanKindFamily, loanKindEcoPTZ, loanKindOther}
+
+// FinancingPlan is the financing of a purchase, made of one or several loan lines. For example, a
+// main loan stacked with a PTZ and an Action Logement loan.
+type FinancingPlan struct {
+	Name string `yaml:"name"`
+
+	// Loans are the loan lines of the plan. Each line is a mortgage, possibly with a deferral.
+	Loans []Mortgage `yaml:"loans"`
+
+	// Smoothed indicates that the repayments are smoothed (prêt lissé): the main loan absorbs the
+	// variations of the other lines, so that the total monthly payment stays constant over the
+	// duration of the main loan.
+	Smoothed bool `yaml:"smoothed"`
+
+	Comment string `yaml:"comment"`
+}
+
+// singleLoanPlan returns the financing plan made of a single mortgage.
+func singleLoanPlan(m Mortgage) FinancingPlan {
+	return FinancingPlan{Name: m.Bank, Loans: []Mortgage{m}}
+}
+
+// financingPlans returns all the financing scenarios of the configuration: each estimated mortgage
+// alone, then the financing plans.
+func (c ImmoConfig) financingPlans() []FinancingPlan {
+	plans := make([]FinancingPlan, 0, len(c.EstimatedMortgages)+len(c.FinancingPlans))
+	for _, m := range c.EstimatedMortgages {
+		plans = append(plans, singleLoanPlan(m))
+	}
+	return append(plans, c.FinancingPlans...)
+}
+
+// Amount returns the total amount borrowed.
+func (p FinancingPlan) Amount() float64 {
+	var total float64
+	for _, l := range p.Loans {
+		total += l.Amount
+	}
+	return total
+}
+
+// HasTerms indicates if all the loan lines have enough information to compute their schedules.
+func (p FinancingPlan) HasTerms() bool {
+	for _, l := range p.Loans {
+		if !l.HasTerms() {
+			return false
+		}
+	}
+	return len(p.Loans) > 0
+}
+
+// mainLoanIndex returns the index of the line absorbing the variations of a smoothed plan: the
+// line of kind "main", or the first line.
+func (p FinancingPlan) mainLoanIndex() int {
+	for i, l := range p.Loans {
+		if l.Kind == loanKindMain {
+			return i
+		}
+	}
+	return 0
+}
+
+// LineSchedules returns the amortization schedule of each loan line. For a smoothed plan, the
+// payment of the main line is computed so that the total payment stays constant.
+func (p FinancingPlan) LineSchedules() [][]AmortizationRow {
+	schedules := make([][]AmortizationRow, len(p.Loans))
+	main := p.mainLoanIndex()
+	for i, l := range p.Loans {
+		if i != main || !p.Smoothed {
+			schedules[i] = l.Schedule()
+		}
+	}
+	if p.Smoothed && len(p.Loans) > 0 {
+		schedules[main] = p.smoothedMainSchedule(schedules)
+	}
+	return schedules
+}
+
+// smoothedMainSchedule returns the schedule of the main line of a smoothed plan. The constant total
+// payment P is the one such that the payments left to the main line, P minus the payments of the
+// other lines, exactly amortize the main loan:
+//
+//	amount = sum over t of (P - others(t)) / (1+r)^t
+func (p FinancingPlan) smoothedMainSchedule(others [][]AmortizationRow) []AmortizationRow {
+	main := p.Loans[p.mainLoanIndex()]
+	if !main.HasTerms() || main.DeferredMonths > 0 {
+		// smoothing is not supported with a deferral of the main line
+		return main.Schedule()
+	}
+	var (
+		r            = main.InterestRate / 12
+		othersByDate = make([]float64, main.Months()+1)
+		discounted   float64
+		annuityUnits float64
+	)
+	for i, rows := range others {
+		if i == p.mainLoanIndex() {
+			continue
+		}
+		for _, row := range rows {
+			if row.Month <= main.Months() {
+				othersByDate[row.Month] += row.Payment
+			}
+		}
+	}
+	for t := 1; t <= main.Months(); t++ {
+		v := math.Pow(1+r, -float64(t))
+		discounted += othersByDate[t] * v
+		annuityUnits += v
+	}
+	total := (main.Amount + discounted) / annuityUnits
+	return main.schedule(func(month int) float64 {
+		return total - othersByDate[month]
+	})
+}
+
+// Schedule returns the combined amortization schedule of all the loan lines, month by month.
+func (p FinancingPlan) Schedule() []AmortizationRow {
+	return combineSchedules(p.LineSchedules())
+}
+
+func combineSchedules(schedules [][]AmortizationRow) []AmortizationRow {
+	var months int
+	for _, rows := range schedules {
+		months = max(months, len(rows))
+	}
+	combined := make([]AmortizationRow, months)
+	for i := range combined {
+		combined[i].Month = i + 1
+	}
+	for _, rows := range schedules {
+		for i, row := range rows {
+			c := &combined[i]
+			c.Payment += row.Payment
+			c.Principal += row.Principal
+			c.Interest += row.Interest
+			c.Insurance += row.Insurance
+			c.RemainingCapital += row.RemainingCapital
+		}
+	}
+	return combined
+}
+
+// MonthlyPayment returns the highest monthly payment of the plan, without insurance, which is the
+// one retained by banks to compute the debt ratio. Lines without terms use their typed monthly
+// cost.
+func (p FinancingPlan) MonthlyPayment() float64 {
+	if len(p.Loans) == 1 {
+		return p.Loans[0].MonthlyPayment()
+	}
+	var (
+		highest float64
+		typed   float64
+		rows    [][]AmortizationRow
+	)
+	for i, schedule := range p.LineSchedules() {
+		if schedule == nil {
+			typed += p.Loans[i].MonthlyCost
+			continue
+		}
+		rows = append(rows, schedule)
+	}
+	for _, row := range combineSchedules(rows) {
+		highest = math.Max(highest, row.Payment)
+	}
+	return highest + typed
+}
+
+// Insurance returns the monthly insurance of the plan, at the first month.
+func (p FinancingPlan) Insurance() float64 {
+	var total float64
+	for _, l := range p.Loans {
+		total += l.MonthlyInsurance()
+	}
+	return total
+}
+
+// TotalInterest returns the total interest paid over the whole duration of the plan.
+func (p FinancingPlan) TotalInterest() float64 {
+	var total float64
+	for i, rows := range p.LineSchedules() {
+		total += totalInterest(rows, p.Loans[i].Amount)
+	}
+	return total
+}
+
+// TotalInsurance returns the total insurance paid over the whole duration of the plan.
+func (p FinancingPlan) TotalInsurance() float64 {
+	var total float64
+	for i, rows := range p.LineSchedules() {
+		if rows == nil {
+			total += p.Loans[i].TotalInsurance()
+			continue
+		}
+		total += totalInsurance(rows)
+	}
+	return total
+}
+
+// lines returns the summary of each loan line of the plan.
+func (p FinancingPlan) lines() []FinancingLine {
+	var lines []FinancingLine
+	for i, rows := range p.LineSchedules() {
+		l := p.Loans[i]
+		line := FinancingLine{
+			Bank:           l.Bank,
+			Kind:           l.Kind,
+			Amount:         math.Round(l.Amount),
+			InterestRate:   l.InterestRate,
+			Years:          l.Years,
+			DeferredMonths: l.DeferredMonths,
+			MonthlyPayment: math.Round(l.MonthlyPayment()),
+			TotalInterest:  math.Round(totalInterest(rows, l.Amount)),
+			TotalInsurance: math.Round(l.TotalInsurance()),
+		}
+		if len(rows) > 0 {
+			// first payment after the deferral
+			line.MonthlyPayment = math.Round(rows[min(l.DeferredMonths, len(rows)-1)].Payment)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,65 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSmoothedPlan(t *testing.T) {
+	plan := FinancingPlan{
+		Smoothed: true,
+		Loans: []Mortgage{
+			{Bank: "main", Kind: loanKindMain, Amount: 200000, InterestRate: 0.035, Years: 20},
+			{Bank: "ptz", Kind: loanKindPTZ, Amount: 40000, Years: 10},
+		},
+	}
+	var (
+		schedules = plan.LineSchedules()
+		combined  = plan.Schedule()
+		main      = schedules[0]
+	)
+	// the total payment is constant over the duration of the main loan
+	for _, row := range combined {
+		if math.Abs(row.Payment-combined[0].Payment) > 0.01 {
+			t.Fatalf("month %d: payment %.2f, want %.2f", row.Month, row.Payment, combined[0].Payment)
+		}
+	}
+	// and the payments left to the main line amortize it exactly: their present value at the rate
+	// of the main loan is its amount
+	var (
+		r          = 0.035 / 12
+		discounted float64
+	)
+	for _, row := range main {
+		discounted += row.Payment * math.Pow(1+r, -float64(row.Month))
+	}
+	if math.Abs(discounted-200000) > 0.01 {
+		t.Errorf("present value of the main line = %.2f, want 200000", discounted)
+	}
+	if got := main[len(main)-1].RemainingCapital; got > 0.005 {
+		t.Errorf("remaining capital of the main line = %.2f, want 0", got)
+	}
+	// the PTZ pays 40000 / 120 = 333.33 per month, so the main line pays less during 10 years
+	if got := schedules[1][0].Payment; math.Abs(got-333.33) > 0.005 {
+		t.Errorf("PTZ payment = %.2f, want 333.33", got)
+	}
+	if main[0].Payment >= main[len(main)-1].Payment {
+		t.Errorf("main payment %.2f during the PTZ, want less than %.2f after", main[0].Payment, main[len(main)-1].Payment)
+	}
+}
+
+func TestPlanWithoutSmoothing(t *testing.T) {
+	plan := FinancingPlan{
+		Loans: []Mortgage{
+			{Bank: "main", Kind: loanKindMain, Amount: 200000, InterestRate: 0.035, Years: 20},
+			{Bank: "ptz", Kind: loanKindPTZ, Amount: 40000, Years: 10},
+		},
+	}
+	// the highest payment is the one of the first 10 years
+	if got, want := plan.MonthlyPayment(), 1159.92+333.33; math.Abs(got-want) > 0.01 {
+		t.Errorf("MonthlyPayment() = %.2f, want %.2f", got, want)
+	}
+	if got := plan.Amount(); got != 240000 {
+		t.Errorf("Amount() = %.0f, want 240000", got)
+	}
+}
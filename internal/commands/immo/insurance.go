@@ -0,0 +1,66 @@
+package immo
+
+// Bases of the borrower insurance rate.
+const (
+	// insuranceBasisInitial means that the rate applies to the initial capital: the insurance is
+	// constant, as in most bank group contracts.
+	insuranceBasisInitial = "initial"
+	// insuranceBasisRemaining means that the rate applies to the remaining capital: the insurance
+	// decreases over the years, as in most delegated contracts (Loi Lemoine).
+	insuranceBasisRemaining = "remaining"
+)
+
+// InsuranceCoverage is the borrower insurance of one person on a mortgage.
+type InsuranceCoverage struct {
+	// Borrower is the name of the insured borrower.
+	Borrower string `yaml:"borrower"`
+
+	// Rate is the annual rate of the insurance, e.g. 0.0034 for 0.34%.
+	Rate float64 `yaml:"rate"`
+
+	// Quotite is the share of the capital covered for this borrower, e.g. 1 for 100% or 0.5 for
+	// 50%. The quotités of all the borrowers must cover at least 100% of the capital.
+	Quotite float64 `yaml:"quotite"`
+
+	// Basis is the capital to which the rate applies: initial (default) or remaining.
+	Basis string `yaml:"basis"`
+}
+
+func (c InsuranceCoverage) basis() string {
+	if c.Basis == insuranceBasisRemaining {
+		return insuranceBasisRemaining
+	}
+	return insuranceBasisInitial
+}
+
+// insurance returns the monthly insurance of the mortgage for a month starting with the given
+// remaining capital. Without coverages, the typed monthly insurance is used.
+func (m Mortgage) insurance(remaining float64) float64 {
+	if len(m.InsuranceCoverages) == 0 {
+		return m.Insurance
+	}
+	var total float64
+	for _, c := range m.InsuranceCoverages {
+		capital := m.Amount
+		if c.basis() == insuranceBasisRemaining {
+			capital = remaining
+		}
+		total += capital * c.Rate / 12 * c.Quotite
+	}
+	return total
+}
+
+// MonthlyInsurance returns the insurance of the first month, which is the highest one. It is the
+// one retained by banks to compute the debt ratio.
+func (m Mortgage) MonthlyInsurance() float64 {
+	return m.insurance(m.Amount)
+}
+
+// quotite returns the total share of the capital covered by the insurance coverages.
+func (m Mortgage) quotite() float64 {
+	var total float64
+	for _, c := range m.InsuranceCoverages {
+		total += c.Quotite
+	}
+	return total
+}
@@ -0,0 +1,93 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMortgageInsurance(t *testing.T) {
+	tests := []struct {
+		name      string
+		coverages []InsuranceCoverage
+		insurance float64 // typed monthly insurance
+		monthly   float64 // insurance of the first month
+		total     float64
+		quotite   float64
+	}{
+		{
+			name:      "typed monthly insurance",
+			insurance: 30,
+			monthly:   30,
+			total:     30 * 240,
+		},
+		{
+			// each borrower covers half of the capital
+			name: "two borrowers at 50%",
+			coverages: []InsuranceCoverage{
+				{Borrower: "A", Rate: 0.003, Quotite: 0.5},
+				{Borrower: "B", Rate: 0.003, Quotite: 0.5},
+			},
+			monthly: 50,
+			total:   50 * 240,
+			quotite: 1,
+		},
+		{
+			// each borrower covers the whole capital, the insurance is paid twice
+			name: "two borrowers at 100%",
+			coverages: []InsuranceCoverage{
+				{Borrower: "A", Rate: 0.003, Quotite: 1},
+				{Borrower: "B", Rate: 0.003, Quotite: 1},
+			},
+			monthly: 100,
+			total:   100 * 240,
+			quotite: 2,
+		},
+		{
+			// the insurance follows the remaining capital, like the interest: its total is the total
+			// interest times the ratio of the rates
+			name:      "remaining capital",
+			coverages: []InsuranceCoverage{{Borrower: "A", Rate: 0.0036, Quotite: 1, Basis: insuranceBasisRemaining}},
+			monthly:   60,
+			total:     78380.66 * 0.0036 / 0.035,
+			quotite:   1,
+		},
+		{
+			name: "partial cover",
+			coverages: []InsuranceCoverage{
+				{Borrower: "A", Rate: 0.003, Quotite: 0.6},
+				{Borrower: "B", Rate: 0.004, Quotite: 0.2, Basis: insuranceBasisInitial},
+			},
+			monthly: 200000*0.003/12*0.6 + 200000*0.004/12*0.2,
+			total:   (200000*0.003/12*0.6 + 200000*0.004/12*0.2) * 240,
+			quotite: 0.8,
+		},
+	}
+	for _, tt := range tests {
+		mortgage := Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20, Insurance: tt.insurance, InsuranceCoverages: tt.coverages}
+		if got := mortgage.MonthlyInsurance(); math.Abs(got-tt.monthly) > 0.005 {
+			t.Errorf("%s: MonthlyInsurance() = %.2f, want %.2f", tt.name, got, tt.monthly)
+		}
+		if got := mortgage.TotalInsurance(); math.Abs(got-tt.total) > 0.1 {
+			t.Errorf("%s: TotalInsurance() = %.2f, want %.2f", tt.name, got, tt.total)
+		}
+		if got := mortgage.quotite(); math.Abs(got-tt.quotite) > 1e-9 {
+			t.Errorf("%s: quotite() = %g, want %g", tt.name, got, tt.quotite)
+		}
+	}
+}
+
+func TestMortgageInsuranceRemainingCapital(t *testing.T) {
+	mortgage := Mortgage{
+		Amount:             200000,
+		InterestRate:       0.035,
+		Years:              20,
+		InsuranceCoverages: []InsuranceCoverage{{Borrower: "A", Rate: 0.0036, Quotite: 1, Basis: insuranceBasisRemaining}},
+	}
+	rows := mortgage.Schedule()
+	// the insurance of a month applies to the capital remaining at its start
+	for _, i := range []int{1, 120, 239} {
+		if got, want := rows[i].Insurance, rows[i-1].RemainingCapital*0.0036/12; math.Abs(got-want) > 1e-9 {
+			t.Errorf("insurance of month %d = %.4f, want %.4f", i+1, got, want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package immo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// matrixMetric is a metric used to rank the goods of the evaluation matrix.
+type matrixMetric struct {
+	value func(e Evaluation, family FamilyContext) float64
+	// descending indicates that higher values are better.
+	descending bool
+}
+
+var matrixMetrics = map[string]matrixMetric{
+	"contribution": {
+		value: func(e Evaluation, _ FamilyContext) float64 { return e.Result.NewPropertyPurchaseCost.Contribution },
+	},
+	"total_purchase_cost": {
+		value: func(e Evaluation, _ FamilyContext) float64 { return e.Result.NewPropertyPurchaseCost.TotalPurchaseCost },
+	},
+	"remaining_assets": {
+		value:      func(e Evaluation, _ FamilyContext) float64 { return e.Result.NewPropertyPurchaseCost.RemainingAssets },
+		descending: true,
+	},
+	"monthly_expenses": {
+		value: func(e Evaluation, family FamilyContext) float64 { return monthlyExpensesDiff(e.Result, family) },
+	},
+	"alerts": {
+		value: func(e Evaluation, _ FamilyContext) float64 { return float64(len(e.Result.Alerts)) },
+	},
+	"debt_ratio": {
+		value: func(e Evaluation, _ FamilyContext) float64 {
+			if e.Result.DebtRatio == nil {
+				return 0
+			}
+			return e.Result.DebtRatio.Ratio
+		},
+	},
+}
+
+func matrixMetricNames() []string {
+	names := make([]string, 0, len(matrixMetrics))
+	for name := range matrixMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func monthlyExpensesDiff(result EvaluationResult, family FamilyContext) float64 {
+	return result.NewPropertyOperationalCost.MonthlyExpenses - family.MonthlyExpenses
+}
+
+// EvaluationMatrix is a summary of an evaluation report: goods are rows and mortgages are columns.
+type EvaluationMatrix struct {
+	Banks                 []string    `yaml:"banks" json:"banks"` // numbered when a bank has several offers, e.g. BNP (2)
+	ContributionThreshold float64     `yaml:"contribution_threshold" json:"contribution_threshold"`
+	SortedBy              string      `yaml:"sorted_by,omitempty" json:"sorted_by,omitempty"`
+	Rows                  []MatrixRow `yaml:"rows" json:"rows"`
+}
+
+type MatrixRow struct {
+	Good  string       `yaml:"good" json:"good"`
+	Cells []MatrixCell `yaml:"cells" json:"cells"`
+}
+
+type MatrixCell struct {
+	Bank                string  `yaml:"bank" json:"bank"`
+	Contribution        float64 `yaml:"contribution" json:"contribution"`
+	MonthlyExpensesDiff float64 `yaml:"monthly_expenses_diff" json:"monthly_expenses_diff"`
+	AlertCount          int     `yaml:"alert_count" json:"alert_count"`
+	AboveThreshold      bool    `yaml:"above_threshold" json:"above_threshold"` // contribution above threshold
+
+	sortValue float64
+}
+
+// buildMatrix summarizes the report as a matrix. When sortBy is set, goods are sorted by their
+// best value of the metric across all mortgages.
+func buildMatrix(report EvaluationReport, family FamilyContext, sortBy string) (EvaluationMatrix, error) {
+	var metric matrixMetric
+	if sortBy != "" {
+		var exists bool
+		if metric, exists = matrixMetrics[sortBy]; !exists {
+			return EvaluationMatrix{}, fmt.Errorf("unsupported sort metric %q, expected one of %v", sortBy, matrixMetricNames())
+		}
+	}
+
+	matrix := EvaluationMatrix{
+		ContributionThreshold: family.ContributionThreshold,
+		SortedBy:              sortBy,
+	}
+	var (
+		rows  = make(map[string]int)
+		banks = make(map[string]int)
+	)
+	for _, e := range report.Evaluations {
+		i, exists := rows[e.Good]
+		if !exists {
+			i = len(matrix.Rows)
+			rows[e.Good] = i
+			matrix.Rows = append(matrix.Rows, MatrixRow{Good: e.Good})
+		}
+		// the evaluations of a good follow the order of the mortgages: the columns are keyed by
+		// position, so that two offers of the same bank are two columns
+		column := len(matrix.Rows[i].Cells)
+		if column == len(matrix.Banks) {
+			banks[e.Bank]++
+			label := e.Bank
+			if n := banks[e.Bank]; n > 1 {
+				label = fmt.Sprintf("%s (%d)", e.Bank, n)
+			}
+			matrix.Banks = append(matrix.Banks, label)
+		}
+		cell := MatrixCell{
+			Bank:                matrix.Banks[column],
+			Contribution:        e.Result.NewPropertyPurchaseCost.Contribution,
+			MonthlyExpensesDiff: monthlyExpensesDiff(e.Result, family),
+			AlertCount:          len(e.Result.Alerts),
+			AboveThreshold:      e.Result.NewPropertyPurchaseCost.Contribution > family.ContributionThreshold,
+		}
+		if metric.value != nil {
+			cell.sortValue = metric.value(e, family)
+		}
+		matrix.Rows[i].Cells = append(matrix.Rows[i].Cells, cell)
+	}
+
+	if metric.value != nil {
+		best := func(row MatrixRow) float64 {
+			v := math.Inf(1)
+			if metric.descending {
+				v = math.Inf(-1)
+			}
+			for _, c := range row.Cells {
+				if metric.descending {
+					v = math.Max(v, c.sortValue)
+				} else {
+					v = math.Min(v, c.sortValue)
+				}
+			}
+			return v
+		}
+		sort.SliceStable(matrix.Rows, func(i, j int) bool {
+			if metric.descending {
+				return best(matrix.Rows[i]) > best(matrix.Rows[j])
+			}
+			return best(matrix.Rows[i]) < best(matrix.Rows[j])
+		})
+	}
+	return matrix, nil
+}
+
+// table renders the matrix. Each cell shows the contribution, the monthly expenses diff and the
+// number of alerts. Cells breaking the contribution threshold are highlighted.
+func (m EvaluationMatrix) table(format outputFormat) table {
+	t := table{headers: append([]string{"good"}, m.Banks...)}
+	for _, row := range m.Rows {
+		cells := make(map[string]MatrixCell)
+		for _, c := range row.Cells {
+			cells[c.Bank] = c
+		}
+		line := []string{row.Good}
+		for _, bank := range m.Banks {
+			c, exists := cells[bank]
+			if !exists {
+				line = append(line, "")
+				continue
+			}
+			line = append(line, c.format(format))
+		}
+		t.rows = append(t.rows, line)
+	}
+	return t
+}
+
+func (c MatrixCell) format(format outputFormat) string {
+	text := fmt.Sprintf("%.0fK / %+.0f / %d alert(s)", math.Round(c.Contribution/1000), c.MonthlyExpensesDiff, c.AlertCount)
+	if !c.AboveThreshold {
+		return text
+	}
+	switch format {
+	case outputMarkdown:
+		return "**" + text + "**"
+	default:
+		return "! " + text
+	}
+}
+
+// legend describes how to read the cells of the matrix.
+func (m EvaluationMatrix) legend() string {
+	var sb strings.Builder
+	sb.WriteString("Cells: contribution / monthly expenses diff / alerts.")
+	fmt.Fprintf(&sb, " Highlighted: contribution above threshold (%.0fK).", math.Round(m.ContributionThreshold/1000))
+	if m.SortedBy != "" {
+		fmt.Fprintf(&sb, " Sorted by %s.", m.SortedBy)
+	}
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package immo
+
+import (
+	"slices"
+	"testing"
+)
+
+func matrixEvaluation(good, bank string, contribution, monthlyExpenses float64) Evaluation {
+	return Evaluation{
+		Good: good,
+		Bank: bank,
+		Result: EvaluationResult{
+			NewPropertyPurchaseCost:    PurchaseCost{Contribution: contribution},
+			NewPropertyOperationalCost: OperationalCost{MonthlyExpenses: monthlyExpenses},
+		},
+	}
+}
+
+func TestBuildMatrix(t *testing.T) {
+	var (
+		family = FamilyContext{MonthlyExpenses: 3000, ContributionThreshold: 80000}
+		report = EvaluationReport{Evaluations: []Evaluation{
+			// two offers of BNP, over 20 and 25 years
+			matrixEvaluation("maison-a", "BNP", 90000, 4200),
+			matrixEvaluation("maison-a", "BNP", 70000, 3900),
+			matrixEvaluation("maison-a", "CIC", 75000, 4000),
+			matrixEvaluation("appart-b", "BNP", 60000, 3600),
+			matrixEvaluation("appart-b", "BNP", 40000, 3300),
+			matrixEvaluation("appart-b", "CIC", 50000, 3500),
+		}}
+	)
+	matrix, err := buildMatrix(report, family, "contribution")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"BNP", "BNP (2)", "CIC"}; !slices.Equal(matrix.Banks, want) {
+		t.Errorf("banks = %v, want %v", matrix.Banks, want)
+	}
+	// appart-b has the lowest contribution
+	if matrix.Rows[0].Good != "appart-b" || matrix.Rows[1].Good != "maison-a" {
+		t.Errorf("rows = %s, %s, want appart-b first", matrix.Rows[0].Good, matrix.Rows[1].Good)
+	}
+	maison := matrix.Rows[1]
+	if len(maison.Cells) != 3 || maison.Cells[0].Contribution != 90000 || maison.Cells[1].Contribution != 70000 {
+		t.Fatalf("cells of maison-a = %+v, want both offers of BNP", maison.Cells)
+	}
+	if !maison.Cells[0].AboveThreshold || maison.Cells[1].AboveThreshold {
+		t.Errorf("above threshold = %t, %t, want true, false", maison.Cells[0].AboveThreshold, maison.Cells[1].AboveThreshold)
+	}
+	if got := maison.Cells[2].MonthlyExpensesDiff; got != 1000 {
+		t.Errorf("monthly expenses diff = %.0f, want 1000", got)
+	}
+
+	tbl := matrix.table(outputMarkdown)
+	want := []string{"maison-a", "**90K / +1200 / 0 alert(s)**", "70K / +900 / 0 alert(s)", "75K / +1000 / 0 alert(s)"}
+	if !slices.Equal(tbl.rows[1], want) {
+		t.Errorf("row of maison-a = %q, want %q", tbl.rows[1], want)
+	}
+
+	if _, err := buildMatrix(report, family, "price"); err == nil {
+		t.Error("buildMatrix() sorted by price succeeded, want an error")
+	}
+}
@@ -0,0 +1,181 @@
+package immo
+
+import "math"
+
+// monthlyCostTolerance is the relative difference accepted between the typed monthly cost of a
+// mortgage and the one computed from its rate and duration. Banks round their rates, so a strict
+// comparison would raise false alerts.
+const monthlyCostTolerance = 0.01
+
+// Deferral types of a loan (différé d'amortissement).
+const (
+	// deferralPartial means that only the interest is paid during the deferral.
+	deferralPartial = "partial"
+	// deferralTotal means that nothing is paid during the deferral, the interest is capitalized.
+	deferralTotal = "total"
+)
+
+// AmortizationRow is one month of a mortgage amortization schedule.
+type AmortizationRow struct {
+	Month            int     `yaml:"month" json:"month"`
+	Payment          float64 `yaml:"payment" json:"payment"` // principal + interest, without insurance
+	Principal        float64 `yaml:"principal" json:"principal"`
+	Interest         float64 `yaml:"interest" json:"interest"`
+	Insurance        float64 `yaml:"insurance" json:"insurance"`
+	RemainingCapital float64 `yaml:"remaining_capital" json:"remaining_capital"`
+}
+
+// Months returns the duration of the mortgage in months, deferral included.
+func (m Mortgage) Months() int {
+	return m.Years * 12
+}
+
+// HasTerms indicates if the mortgage has enough information to compute its payments from the
+// interest rate and the duration, instead of relying on the typed monthly cost.
+//
+// A zero rate is accepted (e.g. PTZ) as long as no monthly cost was typed, otherwise we consider
+// that the rate is simply missing.
+func (m Mortgage) HasTerms() bool {
+	return m.Amount > 0 && m.Years > 0 && m.DeferredMonths < m.Months() && (m.InterestRate > 0 || m.MonthlyCost == 0)
+}
+
+func (m Mortgage) deferralType() string {
+	if m.DeferralType == deferralTotal {
+		return deferralTotal
+	}
+	return deferralPartial
+}
+
+// capitalAfterDeferral returns the capital to amortize once the deferral is over. With a total
+// deferral, the interest of the deferral is added to the capital.
+func (m Mortgage) capitalAfterDeferral() float64 {
+	if m.deferralType() == deferralTotal {
+		return m.Amount * math.Pow(1+m.InterestRate/12, float64(m.DeferredMonths))
+	}
+	return m.Amount
+}
+
+// ComputedMonthlyCost returns the constant monthly payment (principal + interest, without
+// insurance) of the mortgage after the deferral, using the standard annuity formula.
+func (m Mortgage) ComputedMonthlyCost() float64 {
+	return annuity(m.capitalAfterDeferral(), m.InterestRate, m.Months()-m.DeferredMonths)
+}
+
+// annuity returns the constant monthly payment amortizing the capital over the given months.
+func annuity(capital, annualRate float64, months int) float64 {
+	if months <= 0 {
+		return 0
+	}
+	n := float64(months)
+	r := annualRate / 12
+	if r == 0 {
+		return capital / n
+	}
+	return capital * r / (1 - math.Pow(1+r, -n))
+}
+
+// MonthlyPayment returns the monthly payment without insurance. It is computed from the terms of
+// the mortgage when they are available, otherwise the typed monthly cost is used.
+func (m Mortgage) MonthlyPayment() float64 {
+	if m.HasTerms() {
+		return m.ComputedMonthlyCost()
+	}
+	return m.MonthlyCost
+}
+
+// MonthlyCostMismatch indicates if the typed monthly cost disagrees with the computed one.
+func (m Mortgage) MonthlyCostMismatch() bool {
+	if m.MonthlyCost == 0 || !m.HasTerms() {
+		return false
+	}
+	computed := m.ComputedMonthlyCost()
+	return math.Abs(m.MonthlyCost-computed) > computed*monthlyCostTolerance
+}
+
+// Schedule returns the month-by-month amortization schedule of the mortgage. It returns nil when
+// the terms of the mortgage are unknown.
+func (m Mortgage) Schedule() []AmortizationRow {
+	if !m.HasTerms() {
+		return nil
+	}
+	return m.schedule(nil)
+}
+
+// schedule returns the amortization schedule of the mortgage. After the deferral, each month pays
+// the given payment, or the constant annuity when payment is nil. The last month always repays the
+// remaining capital.
+func (m Mortgage) schedule(payment func(month int) float64) []AmortizationRow {
+	var (
+		rows      = make([]AmortizationRow, 0, m.Months())
+		annuity   = m.ComputedMonthlyCost()
+		remaining = m.Amount
+		r         = m.InterestRate / 12
+	)
+	for month := 1; month <= m.Months(); month++ {
+		var (
+			interest  = remaining * r
+			principal float64
+		)
+		switch {
+		case month <= m.DeferredMonths && m.deferralType() == deferralTotal:
+			// the interest is not paid but added to the capital
+			principal = -interest
+		case month <= m.DeferredMonths:
+			principal = 0
+		case month == m.Months():
+			// absorb rounding errors in the last payment
+			principal = remaining
+		case payment != nil:
+			principal = payment(month) - interest
+		default:
+			principal = annuity - interest
+		}
+		insurance := m.insurance(remaining)
+		remaining -= principal
+		rows = append(rows, AmortizationRow{
+			Month:            month,
+			Payment:          principal + interest,
+			Principal:        principal,
+			Interest:         interest,
+			Insurance:        insurance,
+			RemainingCapital: math.Max(remaining, 0),
+		})
+	}
+	return rows
+}
+
+// TotalInterest returns the total interest paid over the whole duration of the mortgage.
+func (m Mortgage) TotalInterest() float64 {
+	return totalInterest(m.Schedule(), m.Amount)
+}
+
+// totalInterest returns the interest paid in a schedule. The capitalized interest of a deferral
+// is counted once, when it is repaid.
+func totalInterest(rows []AmortizationRow, amount float64) float64 {
+	if len(rows) == 0 {
+		return 0
+	}
+	var paid float64
+	for _, row := range rows {
+		paid += row.Payment
+	}
+	return paid - amount
+}
+
+// TotalInsurance returns the total insurance paid over the whole duration of the mortgage. It
+// follows the amortization when the insurance applies to the remaining capital.
+func (m Mortgage) TotalInsurance() float64 {
+	rows := m.Schedule()
+	if rows == nil {
+		return m.MonthlyInsurance() * float64(m.Months())
+	}
+	return totalInsurance(rows)
+}
+
+func totalInsurance(rows []AmortizationRow) float64 {
+	var total float64
+	for _, row := range rows {
+		total += row.Insurance
+	}
+	return total
+}
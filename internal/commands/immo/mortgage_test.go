@@ -0,0 +1,105 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMortgageMonthlyPayment(t *testing.T) {
+	tests := []struct {
+		name     string
+		mortgage Mortgage
+		want     float64
+	}{
+		{
+			name:     "20-year annuity",
+			mortgage: Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20},
+			want:     1159.92,
+		},
+		{
+			name:     "15-year annuity",
+			mortgage: Mortgage{Amount: 100000, InterestRate: 0.04, Years: 15},
+			want:     739.69,
+		},
+		{
+			name:     "zero rate",
+			mortgage: Mortgage{Amount: 60000, Years: 20},
+			want:     250,
+		},
+		{
+			name:     "partial deferral",
+			mortgage: Mortgage{Amount: 100000, InterestRate: 0.03, Years: 20, DeferredMonths: 24},
+			want:     599.72,
+		},
+		{
+			name:     "total deferral",
+			mortgage: Mortgage{Amount: 100000, InterestRate: 0.03, Years: 20, DeferredMonths: 24, DeferralType: deferralTotal},
+			want:     636.76,
+		},
+		{
+			name:     "typed monthly cost without terms",
+			mortgage: Mortgage{Amount: 100000, MonthlyCost: 550},
+			want:     550,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.mortgage.MonthlyPayment(); math.Abs(got-tt.want) > 0.005 {
+			t.Errorf("%s: MonthlyPayment() = %.2f, want %.2f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMortgageSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		mortgage       Mortgage
+		firstPayment   float64 // payment of the first month
+		capitalAfter   float64 // remaining capital at the end of the deferral
+		totalInterest  float64
+		totalInsurance float64
+	}{
+		{
+			name:           "20-year annuity",
+			mortgage:       Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20, Insurance: 30},
+			firstPayment:   1159.92,
+			capitalAfter:   200000,
+			totalInterest:  78380.66,
+			totalInsurance: 30 * 240,
+		},
+		{
+			name:          "partial deferral",
+			mortgage:      Mortgage{Amount: 100000, InterestRate: 0.03, Years: 20, DeferredMonths: 24},
+			firstPayment:  250,
+			capitalAfter:  100000,
+			totalInterest: 24*250 + 216*599.7233 - 100000,
+		},
+		{
+			name:          "total deferral",
+			mortgage:      Mortgage{Amount: 100000, InterestRate: 0.03, Years: 20, DeferredMonths: 24, DeferralType: deferralTotal},
+			firstPayment:  0,
+			capitalAfter:  106175.70,
+			totalInterest: 216*636.7604 - 100000,
+		},
+	}
+	for _, tt := range tests {
+		rows := tt.mortgage.Schedule()
+		if len(rows) != tt.mortgage.Months() {
+			t.Fatalf("%s: %d rows, want %d", tt.name, len(rows), tt.mortgage.Months())
+		}
+		if got := rows[0].Payment; math.Abs(got-tt.firstPayment) > 0.005 {
+			t.Errorf("%s: first payment = %.2f, want %.2f", tt.name, got, tt.firstPayment)
+		}
+		if got := rows[max(tt.mortgage.DeferredMonths-1, 0)].RemainingCapital; tt.mortgage.DeferredMonths > 0 && math.Abs(got-tt.capitalAfter) > 0.005 {
+			t.Errorf("%s: capital after the deferral = %.2f, want %.2f", tt.name, got, tt.capitalAfter)
+		}
+		if got := rows[len(rows)-1].RemainingCapital; got > 0.005 {
+			t.Errorf("%s: remaining capital at the end = %.2f, want 0", tt.name, got)
+		}
+		if got := tt.mortgage.TotalInterest(); math.Abs(got-tt.totalInterest) > 0.1 {
+			t.Errorf("%s: TotalInterest() = %.2f, want %.2f", tt.name, got, tt.totalInterest)
+		}
+		if got := tt.mortgage.TotalInsurance(); got != tt.totalInsurance {
+			t.Errorf("%s: TotalInsurance() = %.2f, want %.2f", tt.name, got, tt.totalInsurance)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var mortgagesCmd = &cobra.Command{
+	Use:   "mortgages",
+	Short: "Commands for the mortgage offers.",
+	RunE:  runImmo,
+}
+
+var mortgagesCompareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Rank the mortgage offers and financing plans by TAEG or total cost of credit.",
+	RunE:  runMortgagesCompare,
+}
+
+var (
+	mortgagesCompareOutput string
+	mortgagesCompareSort   string
+)
+
+// offerMetrics are the metrics used to rank the mortgage offers, lower is better.
+var offerMetrics = map[string]func(o MortgageOffer) float64{
+	"taeg":              func(o MortgageOffer) float64 { return o.TAEG },
+	"total_credit_cost": func(o MortgageOffer) float64 { return o.TotalCreditCost },
+	"monthly_payment":   func(o MortgageOffer) float64 { return o.MonthlyPayment + o.MonthlyInsurance },
+}
+
+func offerMetricNames() []string {
+	names := make([]string, 0, len(offerMetrics))
+	for name := range offerMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	mortgagesCompareCmd.Flags().StringVarP(&mortgagesCompareOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	mortgagesCompareCmd.Flags().StringVar(&mortgagesCompareSort, "sort", "taeg", fmt.Sprintf("Metric used to rank the offers: %s", strings.Join(offerMetricNames(), ", ")))
+	mortgagesCmd.AddCommand(mortgagesCompareCmd)
+}
+
+// MortgageOffer is the cost of a mortgage offer or a financing plan.
+type MortgageOffer struct {
+	Rank             int     `yaml:"rank" json:"rank"`
+	Name             string  `yaml:"name" json:"name"`
+	Amount           float64 `yaml:"amount" json:"amount"`
+	InterestRate     float64 `yaml:"interest_rate" json:"interest_rate"` // weighted by the amount of each line
+	Years            int     `yaml:"years" json:"years"`                 // of the longest line
+	MonthlyPayment   float64 `yaml:"monthly_payment" json:"monthly_payment"`
+	MonthlyInsurance float64 `yaml:"monthly_insurance" json:"monthly_insurance"`
+	Fees             float64 `yaml:"fees" json:"fees"` // application, broker and guarantee
+	TotalInterest    float64 `yaml:"total_interest" json:"total_interest"`
+	TotalInsurance   float64 `yaml:"total_insurance" json:"total_insurance"`
+	TotalCreditCost  float64 `yaml:"total_credit_cost" json:"total_credit_cost"`
+	TAEG             float64 `yaml:"taeg" json:"taeg"`
+}
+
+// MortgageComparison is the ranking of the mortgage offers.
+type MortgageComparison struct {
+	SortedBy string          `yaml:"sorted_by" json:"sorted_by"`
+	Offers   []MortgageOffer `yaml:"offers" json:"offers"`
+}
+
+func runMortgagesCompare(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(mortgagesCompareOutput)
+	if err != nil {
+		return err
+	}
+	metric, ok := offerMetrics[mortgagesCompareSort]
+	if !ok {
+		return fmt.Errorf("unsupported sort %q, expected one of %s", mortgagesCompareSort, strings.Join(offerMetricNames(), ", "))
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+
+	comparison := MortgageComparison{SortedBy: mortgagesCompareSort}
+	for _, plan := range cfg.financingPlans() {
+		if !plan.HasTerms() {
+			fmt.Fprintf(os.Stderr, "Skipping financing %s: interest rate or duration is missing\n", plan.Name)
+			continue
+		}
+		comparison.Offers = append(comparison.Offers, newMortgageOffer(plan))
+	}
+	if len(comparison.Offers) == 0 {
+		return errors.New("no mortgage offer to compare")
+	}
+	sort.SliceStable(comparison.Offers, func(i, j int) bool {
+		return metric(comparison.Offers[i]) < metric(comparison.Offers[j])
+	})
+	for i := range comparison.Offers {
+		comparison.Offers[i].Rank = i + 1
+	}
+
+	if format.isDocument() {
+		return writeDocument(os.Stdout, format, comparison)
+	}
+	return writeTable(os.Stdout, format, comparison.table())
+}
+
+func newMortgageOffer(plan FinancingPlan) MortgageOffer {
+	var (
+		weightedRate float64
+		years        int
+	)
+	for _, l := range plan.Loans {
+		weightedRate += l.InterestRate * l.Amount
+		years = max(years, l.Years)
+	}
+	return MortgageOffer{
+		Name:             plan.Name,
+		Amount:           math.Round(plan.Amount()),
+		InterestRate:     math.Round(weightedRate/plan.Amount()*10000) / 10000,
+		Years:            years,
+		MonthlyPayment:   math.Round(plan.MonthlyPayment()),
+		MonthlyInsurance: math.Round(plan.Insurance()),
+		Fees:             math.Round(plan.fees()),
+		TotalInterest:    math.Round(plan.TotalInterest()),
+		TotalInsurance:   math.Round(plan.TotalInsurance()),
+		TotalCreditCost:  math.Round(plan.TotalCreditCost()),
+		TAEG:             math.Round(plan.TAEG()*10000) / 10000,
+	}
+}
+
+func (c MortgageComparison) table() table {
+	t := table{headers: []string{
+		"rank",
+		"name",
+		"amount",
+		"rate",
+		"years",
+		"monthly_payment",
+		"monthly_insurance",
+		"fees",
+		"total_interest",
+		"total_insurance",
+		"total_credit_cost",
+		"taeg",
+	}}
+	for _, o := range c.Offers {
+		t.append(
+			fmt.Sprint(o.Rank),
+			o.Name,
+			formatAmount(o.Amount),
+			fmt.Sprintf("%.2f%%", o.InterestRate*100),
+			fmt.Sprint(o.Years),
+			formatAmount(o.MonthlyPayment),
+			formatAmount(o.MonthlyInsurance),
+			formatAmount(o.Fees),
+			formatAmount(o.TotalInterest),
+			formatAmount(o.TotalInsurance),
+			formatAmount(o.TotalCreditCost),
+			fmt.Sprintf("%.2f%%", o.TAEG*100),
+		)
+	}
+	return t
+}
@@ -0,0 +1,115 @@
+package immo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"gopkg.in/yaml.v3"
+)
+
+// outputFormat is the format used to print the results of a command.
+type outputFormat string
+
+const (
+	outputText     outputFormat = "text"
+	outputYAML     outputFormat = "yaml"
+	outputJSON     outputFormat = "json"
+	outputCSV      outputFormat = "csv"
+	outputMarkdown outputFormat = "markdown"
+)
+
+var outputFormats = []outputFormat{outputText, outputYAML, outputJSON, outputCSV, outputMarkdown}
+
+func parseOutputFormat(s string) (outputFormat, error) {
+	for _, f := range outputFormats {
+		if string(f) == s {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported output format %q, expected one of %v", s, outputFormats)
+}
+
+// isDocument indicates if the format prints a structured document rather than a table.
+func (f outputFormat) isDocument() bool {
+	return f == outputYAML || f == outputJSON
+}
+
+// table is a tabular representation of results, printed as text, CSV or Markdown.
+type table struct {
+	headers []string
+	rows    [][]string
+}
+
+func (t *table) append(row ...string) {
+	t.rows = append(t.rows, row)
+}
+
+// writeDocument writes a structured document as YAML or JSON.
+func writeDocument(w io.Writer, format outputFormat, v any) error {
+	switch format {
+	case outputYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to write YAML: %w", err)
+		}
+		return enc.Close()
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("failed to write JSON: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("format %q is not a document format", format)
+	}
+}
+
+// writeTable writes a table as aligned text, CSV or Markdown.
+func writeTable(w io.Writer, format outputFormat, t table) error {
+	switch format {
+	case outputText:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, strings.Join(t.headers, "\t"))
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case outputCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(t.headers); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		if err := cw.WriteAll(t.rows); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+		return nil
+	case outputMarkdown:
+		fmt.Fprintf(w, "| %s |\n", strings.Join(escapeMarkdown(t.headers), " | "))
+		fmt.Fprintf(w, "|%s\n", strings.Repeat(" --- |", len(t.headers)))
+		for _, row := range t.rows {
+			fmt.Fprintf(w, "| %s |\n", strings.Join(escapeMarkdown(row), " | "))
+		}
+		return nil
+	default:
+		return fmt.Errorf("format %q is not a table format", format)
+	}
+}
+
+func escapeMarkdown(cells []string) []string {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.ReplaceAll(cell, "|", `\|`)
+	}
+	return escaped
+}
+
+// formatAmount formats an amount of euros for a table cell.
+func formatAmount(v float64) string {
+	return fmt.Sprintf("%.0f", v)
+}
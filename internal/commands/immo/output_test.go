@@ -0,0 +1,85 @@
+package immo
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseOutputFormat(t *testing.T) {
+	for _, f := range outputFormats {
+		if got, err := parseOutputFormat(string(f)); err != nil || got != f {
+			t.Errorf("parseOutputFormat(%q) = %q, %v", f, got, err)
+		}
+	}
+	if _, err := parseOutputFormat("xml"); err == nil {
+		t.Error("parseOutputFormat(xml) succeeded, want an error")
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	tbl := table{headers: []string{"good", "comment"}}
+	tbl.append("maison-a", "below the average, by 5%")
+	tbl.append("appart|b", "")
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{
+			format: outputText,
+			want: "good      comment\n" +
+				"maison-a  below the average, by 5%\n" +
+				"appart|b  \n",
+		},
+		{
+			format: outputCSV,
+			want: "good,comment\n" +
+				"maison-a,\"below the average, by 5%\"\n" +
+				"appart|b,\n",
+		},
+		{
+			format: outputMarkdown,
+			want: "| good | comment |\n" +
+				"| --- | --- |\n" +
+				"| maison-a | below the average, by 5% |\n" +
+				"| appart\\|b |  |\n",
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeTable(&buf, tt.format, tbl); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: writeTable() =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+	if err := writeTable(&bytes.Buffer{}, outputJSON, tbl); err == nil {
+		t.Error("writeTable(json) succeeded, want an error")
+	}
+}
+
+func TestWriteDocument(t *testing.T) {
+	doc := struct {
+		Good  string  `yaml:"good" json:"good"`
+		Price float64 `yaml:"price" json:"price"`
+	}{Good: "maison-a", Price: 450000}
+	tests := []struct {
+		format outputFormat
+		want   string
+	}{
+		{format: outputYAML, want: "good: maison-a\nprice: 450000\n"},
+		{format: outputJSON, want: "{\n  \"good\": \"maison-a\",\n  \"price\": 450000\n}\n"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		if err := writeDocument(&buf, tt.format, doc); err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if got := buf.String(); got != tt.want {
+			t.Errorf("%s: writeDocument() = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if err := writeDocument(&bytes.Buffer{}, outputCSV, doc); err == nil {
+		t.Error("writeDocument(csv) succeeded, want an error")
+	}
+}
@@ -0,0 +1,328 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Project the cash flow and the net worth of each scenario over the years.",
+	RunE:  runProject,
+}
+
+var (
+	projectOutput    string
+	projectYears     int
+	projectStartYear int
+	projectGood      string
+	projectBank      string
+)
+
+func init() {
+	projectCmd.Flags().StringVarP(&projectOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	projectCmd.Flags().IntVar(&projectYears, "years", 20, "Horizon of the projection in years")
+	projectCmd.Flags().IntVar(&projectStartYear, "start-year", time.Now().Year(), "Calendar year of the purchase")
+	projectCmd.Flags().StringVar(&projectGood, "good", "", "Only project the good with this name")
+	projectCmd.Flags().StringVar(&projectBank, "bank", "", "Only project the mortgage of this bank, or the financing plan with this name")
+}
+
+// Assumptions are the economic hypotheses used to project the scenarios over the years. All rates
+// are yearly, e.g. 0.02 for 2%.
+type Assumptions struct {
+	// AppreciationRate is the yearly appreciation of the property value.
+	AppreciationRate float64 `yaml:"appreciation_rate"`
+
+	// InflationRate is the yearly inflation of the living expenses.
+	InflationRate float64 `yaml:"inflation_rate"`
+
+	// RentIncreaseRate is the yearly increase of the rents, usually following the IRL index.
+	RentIncreaseRate float64 `yaml:"rent_increase_rate"`
+
+	// IncomeGrowthRate is the yearly growth of the net incomes of the borrowers.
+	IncomeGrowthRate float64 `yaml:"income_growth_rate"`
+
+	// AssetReturnRate is the yearly return of the remaining assets, after taxes.
+	AssetReturnRate float64 `yaml:"asset_return_rate"`
+}
+
+// yearAssumptions are the assumptions applied to a single year of a projection. They are
+// constant in a deterministic projection, and drawn randomly in a simulation.
+type yearAssumptions struct {
+	Appreciation float64
+	Inflation    float64
+	RentIncrease float64
+	IncomeGrowth float64
+	AssetReturn  float64
+	Vacancy      float64 // share of the year without rental income
+}
+
+// constantAssumptions returns the same assumptions for every year of the horizon.
+func constantAssumptions(a Assumptions, years int) []yearAssumptions {
+	result := make([]yearAssumptions, years)
+	for i := range result {
+		result[i] = yearAssumptions{
+			Appreciation: a.AppreciationRate,
+			Inflation:    a.InflationRate,
+			RentIncrease: a.RentIncreaseRate,
+			IncomeGrowth: a.IncomeGrowthRate,
+			AssetReturn:  a.AssetReturnRate,
+		}
+	}
+	return result
+}
+
+// Projection is the year-by-year projection of a scenario.
+type Projection struct {
+	Good  string           `yaml:"good" json:"good"`
+	Bank  string           `yaml:"bank" json:"bank"`
+	Years []ProjectionYear `yaml:"years" json:"years"`
+}
+
+// ProjectionYear is the state of a scenario at the end of a year.
+type ProjectionYear struct {
+	Year             int     `yaml:"year" json:"year"`
+	Income           float64 `yaml:"income" json:"income"`                   // net incomes of the borrowers
+	RentalIncome     float64 `yaml:"rental_income" json:"rental_income"`     // rents of the current property, after management fees
+	LivingExpenses   float64 `yaml:"living_expenses" json:"living_expenses"` // expenses without the new mortgage
+	MortgagePayments float64 `yaml:"mortgage_payments" json:"mortgage_payments"`
+	CashFlow         float64 `yaml:"cash_flow" json:"cash_flow"`
+	PropertyValue    float64 `yaml:"property_value" json:"property_value"`
+	RemainingCapital float64 `yaml:"remaining_capital" json:"remaining_capital"`
+	Equity           float64 `yaml:"equity" json:"equity"` // property value - remaining capital
+	Assets           float64 `yaml:"assets" json:"assets"`
+	NetWorth         float64 `yaml:"net_worth" json:"net_worth"` // equity + assets
+}
+
+// projectionInput is everything needed to project a scenario.
+type projectionInput struct {
+	evaluation Evaluation
+	family     FamilyContext
+	schedule   []AmortizationRow
+	startYear  int
+	years      []yearAssumptions
+}
+
+// project projects a scenario year by year. It starts at the purchase, with the assets left after
+// the contribution, and each row is the state at the end of a year, the first row being the end of
+// the year of the purchase: the property has appreciated once and twelve payments are made. After
+// the first year, the living expenses follow the inflation and the rents follow the rent increase.
+// Each year, the mortgage follows its amortization, and the assets earn a return and receive the
+// cash flow of the year.
+func project(in projectionInput) Projection {
+	var (
+		result      = in.evaluation.Result
+		rent        float64
+		collected   float64 // rents netted out of the monthly expenses by the evaluation
+		scenario    = rentalScenarioCurrentProperty
+		payment     = in.evaluation.financing.MonthlyPayment()
+		living      = (result.NewPropertyOperationalCost.MonthlyExpenses - payment) * 12
+		income      = in.family.MonthlyNetIncome() * 12
+		value       = in.evaluation.good.Price
+		assets      = result.NewPropertyPurchaseCost.RemainingAssets
+		remaining   = in.evaluation.financing.Amount()
+		projection  = Projection{Good: in.evaluation.Good, Bank: in.evaluation.Bank}
+		monthOffset int
+	)
+	if renting := result.Renting; renting != nil {
+		rent = (renting.MonthlyIncome - renting.GestionFees) * 12
+		collected = rent
+	} else if investment := result.Investment; investment != nil {
+		// the vacancy of the projection replaces the one of the investment
+		rent = in.evaluation.good.Investment.MonthlyRent * 12
+		collected = investment.AnnualRents
+		scenario = rentalScenarioInvestment
+	}
+	// the evaluation nets the rents out of the monthly expenses, put them back
+	living += collected
+
+	// the rents stop when renting is banned, and stay the same when they are frozen
+	var (
+		banYear int
+		frozen  bool
+	)
+	if r := result.rentalRestrictionOf(scenario); r != nil {
+		banYear, frozen = r.BanYear, r.RentFrozen
+	}
+
+	for i, a := range in.years {
+		if i > 0 {
+			living *= 1 + a.Inflation
+			if !frozen {
+				rent *= 1 + a.RentIncrease
+			}
+			income *= 1 + a.IncomeGrowth
+		}
+		value *= 1 + a.Appreciation
+
+		var payments float64
+		for m := monthOffset; m < monthOffset+12 && m < len(in.schedule); m++ {
+			payments += in.schedule[m].Payment + in.schedule[m].Insurance
+			remaining = in.schedule[m].RemainingCapital
+		}
+		monthOffset += 12
+
+		rentalIncome := rent * (1 - a.Vacancy)
+		if banYear > 0 && in.startYear+i >= banYear {
+			rentalIncome = 0
+		}
+		cashFlow := income + rentalIncome - living - payments
+		assets = assets*(1+a.AssetReturn) + cashFlow
+		equity := value - remaining
+
+		projection.Years = append(projection.Years, ProjectionYear{
+			Year:             in.startYear + i,
+			Income:           math.Round(income),
+			RentalIncome:     math.Round(rentalIncome),
+			LivingExpenses:   math.Round(living),
+			MortgagePayments: math.Round(payments),
+			CashFlow:         math.Round(cashFlow),
+			PropertyValue:    math.Round(value),
+			RemainingCapital: math.Round(remaining),
+			Equity:           math.Round(equity),
+			Assets:           math.Round(assets),
+			NetWorth:         math.Round(equity + assets),
+		})
+	}
+	return projection
+}
+
+// ProjectionReport is the structured document of the projections of all scenarios.
+type ProjectionReport struct {
+	StartYear   int          `yaml:"start_year" json:"start_year"`
+	Horizon     int          `yaml:"horizon" json:"horizon"`
+	Projections []Projection `yaml:"projections" json:"projections"`
+}
+
+func runProject(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(projectOutput)
+	if err != nil {
+		return err
+	}
+	if projectYears < 1 || projectYears > 50 {
+		return fmt.Errorf("the horizon must be between 1 and 50 years, got %d", projectYears)
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+	if cfg.Family.MonthlyNetIncome() == 0 {
+		fmt.Fprintln(os.Stderr, "Warning: family.borrowers has no incomes, the cash flow only contains the expenses")
+	}
+
+	report := ProjectionReport{StartYear: projectStartYear, Horizon: projectYears}
+	for _, e := range evaluateAll(cfg).Evaluations {
+		if (projectGood != "" && e.Good != projectGood) || (projectBank != "" && e.Bank != projectBank) {
+			continue
+		}
+		if !e.financing.HasTerms() {
+			fmt.Fprintf(os.Stderr, "Skipping financing %s: interest rate or duration is missing\n", e.Bank)
+			continue
+		}
+		report.Projections = append(report.Projections, project(projectionInput{
+			evaluation: e,
+			family:     cfg.Family,
+			schedule:   e.financing.Schedule(),
+			startYear:  projectStartYear,
+			years:      constantAssumptions(cfg.Assumptions, projectYears),
+		}))
+	}
+	if len(report.Projections) == 0 {
+		return errors.New("no scenario to project")
+	}
+
+	switch format {
+	case outputYAML, outputJSON:
+		return writeDocument(os.Stdout, format, report)
+	case outputCSV:
+		return writeTable(os.Stdout, format, report.table())
+	default:
+		for i, p := range report.Projections {
+			if format == outputMarkdown {
+				fmt.Printf("## %s - %s\n\n", p.Good, p.Bank)
+			} else {
+				fmt.Printf("%d. Projection of %q with %s\n", i+1, p.Good, p.Bank)
+				fmt.Println("==========")
+			}
+			if err := writeTable(os.Stdout, format, p.table()); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		if format == outputMarkdown {
+			fmt.Printf("## Summary after %d years\n\n", report.Horizon)
+		} else {
+			fmt.Printf("Summary after %d years\n", report.Horizon)
+			fmt.Println("==========")
+		}
+		return writeTable(os.Stdout, format, report.summary())
+	}
+}
+
+var projectionHeaders = []string{
+	"year",
+	"income",
+	"rental_income",
+	"living_expenses",
+	"mortgage_payments",
+	"cash_flow",
+	"property_value",
+	"remaining_capital",
+	"equity",
+	"assets",
+	"net_worth",
+}
+
+func (y ProjectionYear) cells() []string {
+	return []string{
+		fmt.Sprint(y.Year),
+		formatAmount(y.Income),
+		formatAmount(y.RentalIncome),
+		formatAmount(y.LivingExpenses),
+		formatAmount(y.MortgagePayments),
+		formatAmount(y.CashFlow),
+		formatAmount(y.PropertyValue),
+		formatAmount(y.RemainingCapital),
+		formatAmount(y.Equity),
+		formatAmount(y.Assets),
+		formatAmount(y.NetWorth),
+	}
+}
+
+func (p Projection) table() table {
+	t := table{headers: projectionHeaders}
+	for _, y := range p.Years {
+		t.append(y.cells()...)
+	}
+	return t
+}
+
+// table returns all the projections in a single table, one row per scenario and year.
+func (r ProjectionReport) table() table {
+	t := table{headers: append([]string{"good", "bank"}, projectionHeaders...)}
+	for _, p := range r.Projections {
+		for _, y := range p.Years {
+			t.append(append([]string{p.Good, p.Bank}, y.cells()...)...)
+		}
+	}
+	return t
+}
+
+// summary compares the scenarios at the end of the horizon.
+func (r ProjectionReport) summary() table {
+	t := table{headers: []string{"good", "bank", "cumulative_cash_flow", "equity", "assets", "net_worth"}}
+	for _, p := range r.Projections {
+		var cashFlow float64
+		for _, y := range p.Years {
+			cashFlow += y.CashFlow
+		}
+		last := p.Years[len(p.Years)-1]
+		t.append(p.Good, p.Bank, formatAmount(cashFlow), formatAmount(last.Equity), formatAmount(last.Assets), formatAmount(last.NetWorth))
+	}
+	return t
+}
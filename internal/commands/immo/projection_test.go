@@ -0,0 +1,138 @@
+package immo
+
+import "testing"
+
+// projectionEvaluation is a purchase of 300000 with a loan of 120000 at 0% over 10 years, i.e.
+// 1000 a month, while the current property is rented 1000 a month with 100 of management fees.
+func projectionEvaluation() Evaluation {
+	mortgage := Mortgage{Bank: "bank", Amount: 120000, Years: 10}
+	return Evaluation{
+		Good: "house",
+		Bank: "bank",
+		Result: EvaluationResult{
+			NewPropertyPurchaseCost:    PurchaseCost{RemainingAssets: 50000},
+			NewPropertyOperationalCost: OperationalCost{MonthlyExpenses: 3000},
+			Renting:                    &RentingPerformance{MonthlyIncome: 1000, GestionFees: 100},
+		},
+		good:      Property{Name: "house", Price: 300000},
+		financing: singleLoanPlan(mortgage),
+	}
+}
+
+func TestProject(t *testing.T) {
+	var (
+		e     = projectionEvaluation()
+		years = constantAssumptions(Assumptions{AppreciationRate: 0.02, InflationRate: 0.1, RentIncreaseRate: 0.05}, 12)
+	)
+	years[1].Vacancy = 0.5
+	projection := project(projectionInput{
+		evaluation: e,
+		family:     FamilyContext{Borrowers: []Borrower{{Name: "A", MonthlyNetIncome: 5000}}},
+		schedule:   e.financing.Schedule(),
+		startYear:  2026,
+		years:      years,
+	})
+	if len(projection.Years) != 12 {
+		t.Fatalf("%d years, want 12", len(projection.Years))
+	}
+
+	// the first row is the end of the year of the purchase: the living expenses are the monthly
+	// expenses without the loan, plus the rents netted out by the evaluation
+	want := ProjectionYear{
+		Year:             2026,
+		Income:           60000,
+		RentalIncome:     10800,
+		LivingExpenses:   34800,
+		MortgagePayments: 12000,
+		CashFlow:         24000,
+		PropertyValue:    306000,
+		RemainingCapital: 108000,
+		Equity:           198000,
+		Assets:           74000,
+		NetWorth:         272000,
+	}
+	if got := projection.Years[0]; got != want {
+		t.Errorf("first year = %+v, want %+v", got, want)
+	}
+
+	// the second year takes the next twelve months of the schedule, with half a year of vacancy
+	want = ProjectionYear{
+		Year:             2027,
+		Income:           60000,
+		RentalIncome:     5670,
+		LivingExpenses:   38280,
+		MortgagePayments: 12000,
+		CashFlow:         15390,
+		PropertyValue:    312120,
+		RemainingCapital: 96000,
+		Equity:           216120,
+		Assets:           89390,
+		NetWorth:         305510,
+	}
+	if got := projection.Years[1]; got != want {
+		t.Errorf("second year = %+v, want %+v", got, want)
+	}
+
+	// the loan is repaid after ten years
+	if got := projection.Years[9]; got.MortgagePayments != 12000 || got.RemainingCapital != 0 {
+		t.Errorf("tenth year: payments %.0f, remaining %.0f, want 12000 and 0", got.MortgagePayments, got.RemainingCapital)
+	}
+	if got := projection.Years[10]; got.MortgagePayments != 0 || got.RemainingCapital != 0 {
+		t.Errorf("eleventh year: payments %.0f, remaining %.0f, want 0", got.MortgagePayments, got.RemainingCapital)
+	}
+}
+
+func TestProjectRentalRestrictions(t *testing.T) {
+	investment := projectionEvaluation()
+	investment.Result.Renting = nil
+	investment.Result.Investment = &InvestmentResult{AnnualRents: 7200}
+	investment.good.Investment = &RentalInvestment{MonthlyRent: 800}
+
+	tests := []struct {
+		name        string
+		evaluation  Evaluation
+		restriction RentalRestriction
+		rents       []float64 // rental income of the first years
+	}{
+		{
+			name:        "rents of the current property follow the rent increase",
+			evaluation:  projectionEvaluation(),
+			restriction: RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "D"},
+			rents:       []float64{10800, 11340, 11907},
+		},
+		{
+			name:        "rents of the current property frozen, then banned",
+			evaluation:  projectionEvaluation(),
+			restriction: RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "F", BanYear: 2028, RentFrozen: true},
+			rents:       []float64{10800, 10800, 0},
+		},
+		{
+			// the restriction of the current property does not apply to the investment
+			name:        "investment rents with a restriction of another scenario",
+			evaluation:  investment,
+			restriction: RentalRestriction{Scenario: rentalScenarioCurrentProperty, Rating: "G", BanYear: 2025, RentFrozen: true},
+			rents:       []float64{9600, 10080, 10584},
+		},
+		{
+			name:        "investment banned",
+			evaluation:  investment,
+			restriction: RentalRestriction{Scenario: rentalScenarioInvestment, Rating: "E", BanYear: 2027},
+			rents:       []float64{9600, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		e := tt.evaluation
+		e.Result.RentalRestrictions = []RentalRestriction{tt.restriction}
+		projection := project(projectionInput{
+			evaluation: e,
+			schedule:   e.financing.Schedule(),
+			startYear:  2026,
+			years:      constantAssumptions(Assumptions{RentIncreaseRate: 0.05}, len(tt.rents)),
+		})
+		for i, want := range tt.rents {
+			if got := projection.Years[i].RentalIncome; got != want {
+				t.Errorf("%s: rental income of %d = %.0f, want %.0f", tt.name, projection.Years[i].Year, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var rankCmd = &cobra.Command{
+	Use:   "rank",
+	Short: "Rank the goods by their score, combining their characteristics and their financials.",
+	RunE:  runRank,
+}
+
+var (
+	rankOutput string
+	rankBank   string
+)
+
+func init() {
+	rankCmd.Flags().StringVarP(&rankOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	rankCmd.Flags().StringVar(&rankBank, "bank", "", "Only score the goods with the mortgage of this bank, or the financing plan with this name (default: the best one of each good)")
+}
+
+// Ranking is the ranking of the goods by score.
+type Ranking struct {
+	Goods []GoodScore `yaml:"goods" json:"goods"`
+}
+
+// GoodScore is the score of a good, with its best financing plan.
+type GoodScore struct {
+	Rank     int              `yaml:"rank" json:"rank"`
+	Good     string           `yaml:"good" json:"good"`
+	Bank     string           `yaml:"bank" json:"bank"`
+	Score    float64          `yaml:"score" json:"score"` // out of 100
+	Criteria []CriterionScore `yaml:"criteria" json:"criteria"`
+}
+
+// CriterionScore is the score of a good on a criterion.
+type CriterionScore struct {
+	Criterion string  `yaml:"criterion" json:"criterion"`
+	Value     string  `yaml:"value" json:"value"`
+	Missing   bool    `yaml:"missing,omitempty" json:"missing,omitempty"` // the value cannot be scored
+	Score     float64 `yaml:"score" json:"score"`                         // between 0 and 1
+	Weight    float64 `yaml:"weight" json:"weight"`
+	Points    float64 `yaml:"points" json:"points"` // share of the score of the good
+}
+
+func runRank(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(rankOutput)
+	if err != nil {
+		return err
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+	if len(cfg.Scoring.Criteria) == 0 {
+		return errors.New("scoring.criteria is empty, define the criteria of the score in the configuration")
+	}
+
+	ranking := rankGoods(evaluateAll(cfg), cfg.Scoring, rankBank)
+	if len(ranking.Goods) == 0 {
+		return errors.New("no good to rank")
+	}
+
+	switch format {
+	case outputYAML, outputJSON:
+		return writeDocument(os.Stdout, format, ranking)
+	case outputCSV:
+		return writeTable(os.Stdout, format, ranking.table())
+	default:
+		if err := writeTable(os.Stdout, format, ranking.summary()); err != nil {
+			return err
+		}
+		fmt.Println()
+		for _, g := range ranking.Goods {
+			if format == outputMarkdown {
+				fmt.Printf("## %d. %s - %s\n\n", g.Rank, g.Good, g.Bank)
+			} else {
+				fmt.Printf("%d. %q with %s: %.1f/100\n", g.Rank, g.Good, g.Bank, g.Score)
+				fmt.Println("==========")
+			}
+			if err := writeTable(os.Stdout, format, g.table()); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		return nil
+	}
+}
+
+// rankGoods scores every good with every financing plan, keeps the best plan of each good, and
+// sorts the goods by decreasing score. The goods with the same score keep their configuration
+// order.
+func rankGoods(report EvaluationReport, scoring ScoringConfig, planName string) Ranking {
+	var (
+		ranking Ranking
+		best    = make(map[string]int) // index of the good in the ranking
+	)
+	for _, e := range report.Evaluations {
+		if planName != "" && e.Bank != planName {
+			continue
+		}
+		score, breakdown := scoreGood(scoring.Criteria, e.good, e.Result)
+		candidate := GoodScore{Good: e.Good, Bank: e.Bank, Score: score, Criteria: breakdown}
+		if i, exists := best[e.Good]; !exists {
+			best[e.Good] = len(ranking.Goods)
+			ranking.Goods = append(ranking.Goods, candidate)
+		} else if score > ranking.Goods[i].Score {
+			ranking.Goods[i] = candidate
+		}
+	}
+	sort.SliceStable(ranking.Goods, func(i, j int) bool {
+		return ranking.Goods[i].Score > ranking.Goods[j].Score
+	})
+	for i := range ranking.Goods {
+		ranking.Goods[i].Rank = i + 1
+	}
+	return ranking
+}
+
+// summary returns the ranking, one row per good.
+func (r Ranking) summary() table {
+	t := table{headers: []string{"rank", "good", "bank", "score"}}
+	for _, g := range r.Goods {
+		t.append(fmt.Sprint(g.Rank), g.Good, g.Bank, fmt.Sprintf("%.1f", g.Score))
+	}
+	return t
+}
+
+var criterionHeaders = []string{"criterion", "value", "score", "weight", "points"}
+
+func (s CriterionScore) cells() []string {
+	value := s.Value
+	if s.Missing {
+		value += " (missing)"
+	}
+	return []string{s.Criterion, value, fmt.Sprintf("%.2f", s.Score), fmt.Sprintf("%g", s.Weight), fmt.Sprintf("%.1f", s.Points)}
+}
+
+// table returns the breakdown of the score of the good by criterion.
+func (g GoodScore) table() table {
+	t := table{headers: criterionHeaders}
+	for _, c := range g.Criteria {
+		t.append(c.cells()...)
+	}
+	return t
+}
+
+// table returns the ranking with the breakdown of every good in a single table.
+func (r Ranking) table() table {
+	t := table{headers: append([]string{"rank", "good", "bank", "total_score"}, criterionHeaders...)}
+	for _, g := range r.Goods {
+		for _, c := range g.Criteria {
+			t.append(append([]string{fmt.Sprint(g.Rank), g.Good, g.Bank, fmt.Sprintf("%.1f", g.Score)}, c.cells()...)...)
+		}
+	}
+	return t
+}
@@ -0,0 +1,266 @@
+package immo
+
+import (
+	"math"
+	"slices"
+)
+
+// Categories of renovation works.
+const (
+	worksInsulation  = "insulation"
+	worksWindows     = "windows"
+	worksHeatPump    = "heat_pump"
+	worksHeating     = "heating" // other heating systems, e.g. pellet stove or boiler
+	worksVentilation = "ventilation"
+	worksKitchen     = "kitchen"
+	worksBathroom    = "bathroom"
+	worksOther       = "other"
+)
+
+var worksCategories = []string{worksInsulation, worksWindows, worksHeatPump, worksHeating, worksVentilation, worksKitchen, worksBathroom, worksOther}
+
+// Income brackets of the household for the renovation subsidies, named after the colors of the
+// ANAH: blue, yellow, purple and pink.
+const (
+	bracketVeryModest   = "very_modest"
+	bracketModest       = "modest"
+	bracketIntermediate = "intermediate"
+	bracketHigh         = "high"
+)
+
+var incomeBrackets = []string{bracketVeryModest, bracketModest, bracketIntermediate, bracketHigh}
+
+// subsidyRule is a subsidy for a category of works: a share of the cost, within a cap.
+type subsidyRule struct {
+	rate float64
+	cap  float64
+}
+
+func (r subsidyRule) amount(cost float64) float64 {
+	return math.Min(cost*r.rate, r.cap)
+}
+
+// maPrimeRenovRules approximate the flat amounts of MaPrimeRénov' per work (par geste), by
+// category and income bracket. High incomes are not eligible since 2024.
+var maPrimeRenovRules = map[string]map[string]subsidyRule{
+	worksInsulation: {
+		bracketVeryModest:   {rate: 0.50, cap: 15000},
+		bracketModest:       {rate: 0.40, cap: 12000},
+		bracketIntermediate: {rate: 0.25, cap: 7500},
+	},
+	worksWindows: {
+		bracketVeryModest:   {rate: 0.15, cap: 1000},
+		bracketModest:       {rate: 0.12, cap: 800},
+		bracketIntermediate: {rate: 0.06, cap: 400},
+	},
+	worksHeatPump: {
+		bracketVeryModest:   {rate: 1, cap: 5000},
+		bracketModest:       {rate: 1, cap: 4000},
+		bracketIntermediate: {rate: 1, cap: 3000},
+	},
+	worksHeating: {
+		bracketVeryModest:   {rate: 1, cap: 2500},
+		bracketModest:       {rate: 1, cap: 2000},
+		bracketIntermediate: {rate: 1, cap: 1000},
+	},
+	worksVentilation: {
+		bracketVeryModest:   {rate: 1, cap: 2500},
+		bracketModest:       {rate: 1, cap: 2000},
+		bracketIntermediate: {rate: 1, cap: 1500},
+	},
+}
+
+// ceeRules approximate the bonuses of the energy saving certificates (CEE, primes énergie), paid
+// by the energy suppliers to all the households, with a boost for the modest ones.
+var ceeRules = map[string]map[string]subsidyRule{
+	worksInsulation: {
+		bracketVeryModest:   {rate: 0.25, cap: 8000},
+		bracketModest:       {rate: 0.25, cap: 8000},
+		bracketIntermediate: {rate: 0.15, cap: 5000},
+		bracketHigh:         {rate: 0.15, cap: 5000},
+	},
+	worksWindows: {
+		bracketVeryModest:   {rate: 0.05, cap: 500},
+		bracketModest:       {rate: 0.05, cap: 500},
+		bracketIntermediate: {rate: 0.03, cap: 300},
+		bracketHigh:         {rate: 0.03, cap: 300},
+	},
+	worksHeatPump: {
+		bracketVeryModest:   {rate: 1, cap: 4000},
+		bracketModest:       {rate: 1, cap: 4000},
+		bracketIntermediate: {rate: 1, cap: 2500},
+		bracketHigh:         {rate: 1, cap: 2500},
+	},
+	worksHeating: {
+		bracketVeryModest:   {rate: 1, cap: 1000},
+		bracketModest:       {rate: 1, cap: 1000},
+		bracketIntermediate: {rate: 1, cap: 500},
+		bracketHigh:         {rate: 1, cap: 500},
+	},
+}
+
+// ecoPTZCaps are the maximum amounts of the eco-PTZ, a zero-rate loan for energy works, by number
+// of eligible works. A global renovation reaching 35% of energy savings is capped at 50,000.
+var ecoPTZCaps = []float64{0, 15000, 25000, 30000}
+
+const (
+	ecoPTZGlobalCap         = 50000
+	ecoPTZGlobalSavingsRate = 0.35
+	ecoPTZMaxYears          = 20
+)
+
+// energyWorks indicates if a category of works improves the energy performance, and is eligible
+// to the eco-PTZ.
+func energyWorks(category string) bool {
+	switch category {
+	case worksInsulation, worksWindows, worksHeatPump, worksHeating, worksVentilation:
+		return true
+	default:
+		return false
+	}
+}
+
+// RenovationItem is a line of the renovation works of a good.
+type RenovationItem struct {
+	Name string `yaml:"name"`
+
+	// Category is the category of works: insulation, windows, heat_pump, heating, ventilation,
+	// kitchen, bathroom or other. Only the energy works are eligible to the subsidies.
+	Category string `yaml:"category"`
+
+	// Cost is the cost of the works, taxes included.
+	Cost float64 `yaml:"cost"`
+
+	// ConsumptionSaving is the expected saving of energy in kWh of primary energy per m² per year.
+	ConsumptionSaving float64 `yaml:"consumption_saving"`
+}
+
+// dpeThresholds are the highest consumptions of each DPE letter, in kWh of primary energy per m²
+// per year. The thresholds on the greenhouse gas emissions are ignored.
+var dpeThresholds = []struct {
+	rating      string
+	consumption float64
+}{
+	{"A", 70},
+	{"B", 110},
+	{"C", 180},
+	{"D", 250},
+	{"E", 330},
+	{"F", 420},
+}
+
+// dpeRatingOf returns the DPE letter of a consumption in kWh of primary energy per m² per year.
+func dpeRatingOf(consumption float64) string {
+	for _, t := range dpeThresholds {
+		if consumption <= t.consumption {
+			return t.rating
+		}
+	}
+	return "G"
+}
+
+func (f FamilyContext) incomeBracket() string {
+	if f.IncomeBracket != "" {
+		return f.IncomeBracket
+	}
+	return bracketHigh
+}
+
+// planRenovation returns the detail of the renovation items of a good, with the subsidies of the
+// household. It returns nil when the renovation is not itemized.
+func planRenovation(good Property, incomeBracket string) *RenovationPlan {
+	if len(good.RenovationItems) == 0 {
+		return nil
+	}
+	var (
+		plan        = &RenovationPlan{IncomeBracket: incomeBracket}
+		saving      float64
+		energyCount int
+		energyCost  float64
+	)
+	for _, item := range good.RenovationItems {
+		line := RenovationLine{
+			Name:         item.Name,
+			Category:     item.Category,
+			Cost:         math.Round(item.Cost),
+			MaPrimeRenov: math.Round(maPrimeRenovRules[item.Category][incomeBracket].amount(item.Cost)),
+			CEE:          math.Round(ceeRules[item.Category][incomeBracket].amount(item.Cost)),
+		}
+		// the subsidies cannot exceed the cost
+		line.CEE = math.Min(line.CEE, line.Cost-line.MaPrimeRenov)
+		line.NetCost = line.Cost - line.MaPrimeRenov - line.CEE
+		plan.Items = append(plan.Items, line)
+
+		plan.GrossCost += line.Cost
+		plan.MaPrimeRenov += line.MaPrimeRenov
+		plan.CEE += line.CEE
+		plan.NetCost += line.NetCost
+		saving += item.ConsumptionSaving
+		if energyWorks(item.Category) {
+			energyCount++
+			energyCost += item.Cost
+		}
+	}
+
+	consumption, _ := consumptionOf(good.EnergyConsumption, good.EnergyPerformanceRating)
+	if consumption > 0 && saving > 0 {
+		plan.ConsumptionBefore = math.Round(consumption)
+		plan.ConsumptionAfter = math.Round(math.Max(consumption-saving, 0))
+		plan.RatingAfter = dpeRatingOf(plan.ConsumptionAfter)
+	}
+
+	ecoPTZCap := ecoPTZCaps[min(energyCount, len(ecoPTZCaps)-1)]
+	if consumption > 0 && saving/consumption >= ecoPTZGlobalSavingsRate {
+		ecoPTZCap = ecoPTZGlobalCap
+	}
+	plan.EcoPTZ = math.Round(math.Min(energyCost, ecoPTZCap))
+	return plan
+}
+
+// withEcoPTZ returns the financing plan with the eco-PTZ of the renovation of the good, when the
+// good borrows it. A plan which already has an eco-PTZ line is returned as is.
+func (p FinancingPlan) withEcoPTZ(good Property, renovation *RenovationPlan) FinancingPlan {
+	if good.EcoPTZYears == 0 || renovation == nil || renovation.EcoPTZ == 0 {
+		return p
+	}
+	for _, l := range p.Loans {
+		if l.Kind == loanKindEcoPTZ {
+			return p
+		}
+	}
+	p.Loans = append(slices.Clone(p.Loans), Mortgage{
+		Bank:   "eco-PTZ",
+		Kind:   loanKindEcoPTZ,
+		Amount: renovation.EcoPTZ,
+		Years:  good.EcoPTZYears,
+	})
+	return p
+}
+
+// installs indicates if the renovation includes works of the given category.
+func (p *RenovationPlan) installs(category string) bool {
+	if p == nil {
+		return false
+	}
+	for _, item := range p.Items {
+		if item.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+// withRenovation returns the good once its itemized renovation is planned: the renovation cost is
+// the net cost after subsidies, and the consumption after renovation is the one expected from the
+// works, unless they are typed in the configuration.
+func (p Property) withRenovation(plan *RenovationPlan) Property {
+	if plan == nil {
+		return p
+	}
+	p.RenovationCost = plan.NetCost
+	if plan.RatingAfter != "" && p.EnergyConsumptionAfterRenovation == 0 && p.EnergyPerformanceRatingAfterRenovation == "" {
+		p.EnergyConsumptionAfterRenovation = plan.ConsumptionAfter
+		p.EnergyPerformanceRatingAfterRenovation = plan.RatingAfter
+	}
+	return p
+}
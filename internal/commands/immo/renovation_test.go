@@ -0,0 +1,180 @@
+package immo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPlanRenovation(t *testing.T) {
+	tests := []struct {
+		name    string
+		good    Property
+		bracket string
+		want    *RenovationPlan
+	}{
+		{
+			name: "no renovation items",
+			good: Property{RenovationCost: 20000},
+			want: nil,
+		},
+		{
+			// the heat pump is fully paid by MaPrimeRénov', so its CEE is cut to nothing; the global
+			// renovation saves 37.5% of the energy, which raises the eco-PTZ cap to 50000
+			name: "global renovation",
+			good: Property{
+				EnergyConsumption: 400,
+				RenovationItems: []RenovationItem{
+					{Name: "attic", Category: worksInsulation, Cost: 20000, ConsumptionSaving: 100},
+					{Name: "pac", Category: worksHeatPump, Cost: 3000, ConsumptionSaving: 50},
+					{Name: "kitchen", Category: worksKitchen, Cost: 10000},
+				},
+			},
+			bracket: bracketModest,
+			want: &RenovationPlan{
+				IncomeBracket: bracketModest,
+				Items: []RenovationLine{
+					{Name: "attic", Category: worksInsulation, Cost: 20000, MaPrimeRenov: 8000, CEE: 5000, NetCost: 7000},
+					{Name: "pac", Category: worksHeatPump, Cost: 3000, MaPrimeRenov: 3000, CEE: 0, NetCost: 0},
+					{Name: "kitchen", Category: worksKitchen, Cost: 10000, NetCost: 10000},
+				},
+				GrossCost:         33000,
+				MaPrimeRenov:      11000,
+				CEE:               5000,
+				NetCost:           17000,
+				EcoPTZ:            23000,
+				ConsumptionBefore: 400,
+				ConsumptionAfter:  250,
+				RatingAfter:       "D",
+			},
+		},
+		{
+			// both subsidies reach their caps, and a single work caps the eco-PTZ at 15000
+			name: "capped subsidies",
+			good: Property{
+				RenovationItems: []RenovationItem{{Name: "walls", Category: worksInsulation, Cost: 40000}},
+			},
+			bracket: bracketVeryModest,
+			want: &RenovationPlan{
+				IncomeBracket: bracketVeryModest,
+				Items:         []RenovationLine{{Name: "walls", Category: worksInsulation, Cost: 40000, MaPrimeRenov: 15000, CEE: 8000, NetCost: 17000}},
+				GrossCost:     40000,
+				MaPrimeRenov:  15000,
+				CEE:           8000,
+				NetCost:       17000,
+				EcoPTZ:        15000,
+			},
+		},
+		{
+			// high incomes only get the CEE, and three works cap the eco-PTZ at 30000
+			name: "high income",
+			good: Property{
+				EnergyConsumption: 300,
+				RenovationItems: []RenovationItem{
+					{Name: "attic", Category: worksInsulation, Cost: 20000, ConsumptionSaving: 40},
+					{Name: "windows", Category: worksWindows, Cost: 8000, ConsumptionSaving: 20},
+					{Name: "vmc", Category: worksVentilation, Cost: 5000},
+				},
+			},
+			bracket: bracketHigh,
+			want: &RenovationPlan{
+				IncomeBracket: bracketHigh,
+				Items: []RenovationLine{
+					{Name: "attic", Category: worksInsulation, Cost: 20000, CEE: 3000, NetCost: 17000},
+					{Name: "windows", Category: worksWindows, Cost: 8000, CEE: 240, NetCost: 7760},
+					{Name: "vmc", Category: worksVentilation, Cost: 5000, NetCost: 5000},
+				},
+				GrossCost:         33000,
+				CEE:               3240,
+				NetCost:           29760,
+				EcoPTZ:            30000,
+				ConsumptionBefore: 300,
+				ConsumptionAfter:  240,
+				RatingAfter:       "D",
+			},
+		},
+	}
+	for _, tt := range tests {
+		if got := planRenovation(tt.good, tt.bracket); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: planRenovation() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithEcoPTZ(t *testing.T) {
+	var (
+		// the spare capacity would receive the eco-PTZ if the loans were appended in place
+		loans      = append(make([]Mortgage, 0, 2), Mortgage{Bank: "BNP", Amount: 300000})
+		plan       = FinancingPlan{Name: "BNP", Loans: loans}
+		renovation = &RenovationPlan{EcoPTZ: 23000}
+		ecoPTZ     = Mortgage{Bank: "eco-PTZ", Kind: loanKindEcoPTZ, Amount: 23000, Years: 15}
+	)
+	tests := []struct {
+		name       string
+		plan       FinancingPlan
+		good       Property
+		renovation *RenovationPlan
+		want       []Mortgage
+	}{
+		{
+			name:       "eco-PTZ not borrowed",
+			plan:       plan,
+			renovation: renovation,
+			want:       plan.Loans,
+		},
+		{
+			name:       "eco-PTZ borrowed",
+			plan:       plan,
+			good:       Property{EcoPTZYears: 15},
+			renovation: renovation,
+			want:       []Mortgage{plan.Loans[0], ecoPTZ},
+		},
+		{
+			name: "no renovation",
+			plan: plan,
+			good: Property{EcoPTZYears: 15},
+			want: plan.Loans,
+		},
+		{
+			name:       "eco-PTZ already in the plan",
+			plan:       FinancingPlan{Name: "BNP", Loans: []Mortgage{plan.Loans[0], ecoPTZ}},
+			good:       Property{EcoPTZYears: 20},
+			renovation: renovation,
+			want:       []Mortgage{plan.Loans[0], ecoPTZ},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.plan.withEcoPTZ(tt.good, tt.renovation); !reflect.DeepEqual(got.Loans, tt.want) {
+			t.Errorf("%s: loans = %+v, want %+v", tt.name, got.Loans, tt.want)
+		}
+	}
+	// the loans of the configuration are shared by all the goods, they must not be modified
+	if spare := loans[:2][1]; !reflect.DeepEqual(spare, Mortgage{}) {
+		t.Errorf("withEcoPTZ() modified the loans of the plan: %+v", spare)
+	}
+}
+
+func TestEvaluateEcoPTZ(t *testing.T) {
+	cfg := ImmoConfig{
+		Family:             FamilyContext{MonthlyExpenses: 3000, IncomeBracket: bracketModest},
+		EstimatedMortgages: []Mortgage{{Bank: "BNP", Amount: 300000, InterestRate: 0.035, Years: 25}},
+		Goods: []Property{{
+			Name:                    "maison-a",
+			Price:                   350000,
+			ZipCode:                 "92160",
+			EnergyPerformanceRating: "F",
+			EcoPTZYears:             15,
+			RenovationItems:         []RenovationItem{{Name: "walls", Category: worksInsulation, Cost: 20000}},
+		}},
+	}
+	e := evaluateAll(cfg).Evaluations[0]
+	// the eco-PTZ is borrowed once, and the evaluation keeps the plan it evaluated
+	if got := len(e.financing.Loans); got != 2 {
+		t.Fatalf("%d loans, want the mortgage and the eco-PTZ", got)
+	}
+	if got := e.financing.Loans[1]; got.Kind != loanKindEcoPTZ || got.Amount != 15000 || got.Years != 15 {
+		t.Errorf("eco-PTZ = %+v, want 15000 over 15 years", got)
+	}
+	if got := e.Result.NewPropertyPurchaseCost.MortgageAmount; got != 315000 {
+		t.Errorf("mortgage amount = %.0f, want 315000", got)
+	}
+}
@@ -0,0 +1,219 @@
+package immo
+
+import "math"
+
+// Tax regimes of rental incomes.
+const (
+	regimeMicroFoncier = "micro_foncier"  // unfurnished, 30% allowance
+	regimeReel         = "reel"           // unfurnished, real expenses
+	regimeMicroBIC     = "lmnp_micro_bic" // furnished (LMNP), 50% allowance
+	regimeLMNPReel     = "lmnp_reel"      // furnished (LMNP), real expenses and depreciation
+)
+
+var rentalRegimes = []string{regimeMicroFoncier, regimeReel, regimeMicroBIC, regimeLMNPReel}
+
+const (
+	// socialLeviesRate is the rate of the social levies (prélèvements sociaux) on rental incomes.
+	socialLeviesRate = 0.172
+
+	microFoncierAllowance = 0.30
+	microFoncierCeiling   = 15000
+	microBICAllowance     = 0.50
+	microBICCeiling       = 77700
+
+	// landDeficitCeiling is the maximum land deficit (déficit foncier) deducted from the global
+	// income each year. The part coming from the interest is only deducted from future rents.
+	landDeficitCeiling = 10700
+
+	// defaultLandShare is the share of the price corresponding to the land, which is not
+	// depreciated.
+	defaultLandShare = 0.15
+
+	// depreciation durations in years, for the LMNP réel regime
+	buildingDepreciationYears  = 30
+	worksDepreciationYears     = 15
+	furnitureDepreciationYears = 7
+)
+
+// RentalInvestment describes a good evaluated as a rental investment rather than as the home of
+// the family.
+type RentalInvestment struct {
+	// MonthlyRent is the expected monthly rent, without the recoverable charges.
+	MonthlyRent float64 `yaml:"monthly_rent"`
+
+	// VacancyRate is the expected share of the year without tenant, e.g. 0.05 for about 2 weeks.
+	VacancyRate float64 `yaml:"vacancy_rate"`
+
+	// MonthlyCharges are the monthly charges not recoverable from the tenant.
+	MonthlyCharges float64 `yaml:"monthly_charges"`
+
+	// ManagementFeesRate is the rate of the management fees, applied to the rents collected.
+	ManagementFeesRate float64 `yaml:"management_fees_rate"`
+
+	// AnnualInsurance is the annual cost of the landlord insurances (PNO, unpaid rents).
+	AnnualInsurance float64 `yaml:"annual_insurance"`
+
+	// LandShare is the share of the price corresponding to the land, which is not depreciated in
+	// the LMNP réel regime. Default to 15%.
+	LandShare float64 `yaml:"land_share"`
+}
+
+func (r RentalInvestment) landShare() float64 {
+	if r.LandShare > 0 {
+		return r.LandShare
+	}
+	return defaultLandShare
+}
+
+// rentalIncome is the yearly income of a rental, used to compute its taxes.
+type rentalIncome struct {
+	Rents        float64 // rents collected
+	Expenses     float64 // deductible expenses: charges, management, insurances, property tax
+	Interest     float64 // interest and insurance of the loan
+	Depreciation float64 // depreciation of the building, the works and the furniture
+}
+
+// rentalTaxes returns the taxes of a rental under the given regime, with the marginal tax rate of
+// the family. The taxes are negative when a deficit reduces the income tax of the family.
+func rentalTaxes(regime string, in rentalIncome, marginalTaxRate float64) RentalTax {
+	tax := RentalTax{Regime: regime, Eligible: true}
+	switch regime {
+	case regimeMicroFoncier:
+		tax.Eligible = in.Rents <= microFoncierCeiling
+		tax.TaxableIncome = in.Rents * (1 - microFoncierAllowance)
+	case regimeReel:
+		tax.TaxableIncome = in.Rents - in.Expenses - in.Interest
+		if tax.TaxableIncome < 0 {
+			// the interest is deducted from the rents first, the remaining deficit is deducted
+			// from the global income within the ceiling
+			deficit := math.Min(math.Max(in.Expenses-math.Max(in.Rents-in.Interest, 0), 0), landDeficitCeiling)
+			tax.IncomeTax = -deficit * marginalTaxRate
+			tax.Total = tax.IncomeTax
+			return tax
+		}
+	case regimeMicroBIC:
+		tax.Eligible = in.Rents <= microBICCeiling
+		tax.TaxableIncome = in.Rents * (1 - microBICAllowance)
+	case regimeLMNPReel:
+		// the depreciation cannot create a deficit, the deficit of the expenses is only deducted
+		// from future furnished rents
+		beforeDepreciation := in.Rents - in.Expenses - in.Interest
+		tax.TaxableIncome = math.Max(beforeDepreciation-math.Min(in.Depreciation, math.Max(beforeDepreciation, 0)), 0)
+	}
+	tax.TaxableIncome = math.Max(tax.TaxableIncome, 0)
+	tax.IncomeTax = tax.TaxableIncome * marginalTaxRate
+	tax.SocialLevies = tax.TaxableIncome * socialLeviesRate
+	tax.Total = tax.IncomeTax + tax.SocialLevies
+	return tax
+}
+
+// annualDepreciation returns the yearly depreciation of a furnished rental in the LMNP réel
+// regime: the building and the acquisition fees, without the land, the works and the furniture.
+func annualDepreciation(good Property, fees AcquisitionFees, investment RentalInvestment) float64 {
+	building := (good.Price - good.FurnitureValue + fees.Total) * (1 - investment.landShare())
+	return building/buildingDepreciationYears +
+		good.RenovationCost/worksDepreciationYears +
+		(good.FurnitureValue+good.FournitureCost)/furnitureDepreciationYears
+}
+
+// firstYearInterest returns the interest and the insurance paid the first year of a schedule.
+func firstYearInterest(rows []AmortizationRow) float64 {
+	var total float64
+	for _, row := range rows[:min(12, len(rows))] {
+		total += row.Interest + row.Insurance
+	}
+	return total
+}
+
+// computeInvestment evaluates a good as a rental investment, over its first year. It returns nil
+// when the good is not a rental investment.
+func computeInvestment(ctx EvaluationContext, good Property, fees AcquisitionFees, purchaseCost float64) *InvestmentResult {
+	if good.Investment == nil {
+		return nil
+	}
+	var (
+		investment = *good.Investment
+		rents      = investment.MonthlyRent * 12 * (1 - investment.VacancyRate)
+		expenses   = investment.MonthlyCharges*12 +
+			rents*investment.ManagementFeesRate +
+			investment.AnnualInsurance +
+			good.AnnualPropertyTax
+		loanCost = (ctx.Financing.MonthlyPayment() + ctx.Financing.Insurance()) * 12
+		cashFlow = rents - expenses - loanCost
+		income   = rentalIncome{
+			Rents:        rents,
+			Expenses:     expenses,
+			Interest:     firstYearInterest(ctx.Financing.Schedule()),
+			Depreciation: annualDepreciation(good, fees, investment),
+		}
+		result = &InvestmentResult{
+			AnnualRents:     math.Round(rents),
+			AnnualExpenses:  math.Round(expenses),
+			AnnualLoanCost:  math.Round(loanCost),
+			GrossYield:      math.Round(investment.MonthlyRent*12/good.Price*10000) / 10000,
+			NetYield:        math.Round((rents-expenses)/purchaseCost*10000) / 10000,
+			MonthlyCashFlow: math.Round(cashFlow / 12),
+		}
+		best *RentalTax
+	)
+	for _, regime := range rentalRegimes {
+		tax := rentalTaxes(regime, income, ctx.Family.MarginalTaxRate).rounded()
+		tax.MonthlyCashFlow = math.Round((cashFlow - tax.Total) / 12)
+		result.Regimes = append(result.Regimes, tax)
+	}
+	for i, tax := range result.Regimes {
+		if tax.Eligible && (best == nil || tax.Total < best.Total) {
+			best = &result.Regimes[i]
+		}
+	}
+	if best != nil {
+		result.BestRegime = best.Regime
+	}
+	return result
+}
+
+// currentPropertyTax returns the yearly taxes of the rents of the current property, under its
+// tax regime. In the réel regime, the interest of the first year of the current loan is deducted.
+func currentPropertyTax(cp CurrentPropertyContext, marginalTaxRate float64) RentalTax {
+	regime := regimeMicroFoncier
+	if cp.RentalRegime == regimeReel {
+		regime = regimeReel
+	}
+	income := rentalIncome{
+		Rents:    cp.MonthlyIncome * 12,
+		Expenses: (cp.MonthlyCharges+(cp.MonthlyIncome-cp.MonthlyCharges)*cp.GestionFeesRate)*12 + cp.AnnualPropertyTax,
+		Interest: firstYearInterest(currentLoanSchedule(cp)),
+	}
+	return rentalTaxes(regime, income, marginalTaxRate)
+}
+
+// currentLoanSchedule returns the amortization schedule of the loan of the current property, from
+// its remaining capital, its rate and its monthly payment.
+func currentLoanSchedule(cp CurrentPropertyContext) []AmortizationRow {
+	var (
+		rows      []AmortizationRow
+		remaining = cp.RemainingLoanCapital
+		r         = cp.LoanInterestRate / 12
+	)
+	for month := 1; remaining > 0 && cp.MonthlyMortgage > remaining*r; month++ {
+		interest := remaining * r
+		principal := math.Min(cp.MonthlyMortgage-interest, remaining)
+		remaining -= principal
+		rows = append(rows, AmortizationRow{
+			Month:            month,
+			Payment:          principal + interest,
+			Principal:        principal,
+			Interest:         interest,
+			RemainingCapital: remaining,
+		})
+	}
+	return rows
+}
+
+func (t RentalTax) rounded() RentalTax {
+	t.TaxableIncome = math.Round(t.TaxableIncome)
+	t.IncomeTax = math.Round(t.IncomeTax)
+	t.SocialLevies = math.Round(t.SocialLevies)
+	t.Total = math.Round(t.Total)
+	return t
+}
@@ -0,0 +1,96 @@
+package immo
+
+import "testing"
+
+func TestRentalTaxes(t *testing.T) {
+	const marginalTaxRate = 0.30
+	tests := []struct {
+		name   string
+		regime string
+		income rentalIncome
+		want   RentalTax
+	}{
+		{
+			name:   "micro-foncier, 30% allowance",
+			regime: regimeMicroFoncier,
+			income: rentalIncome{Rents: 10000, Expenses: 3000},
+			want:   RentalTax{Eligible: true, TaxableIncome: 7000, IncomeTax: 2100, SocialLevies: 1204, Total: 3304},
+		},
+		{
+			name:   "micro-foncier above the ceiling",
+			regime: regimeMicroFoncier,
+			income: rentalIncome{Rents: 16000},
+			want:   RentalTax{Eligible: false, TaxableIncome: 11200, IncomeTax: 3360, SocialLevies: 1926, Total: 5286},
+		},
+		{
+			name:   "réel, expenses and interest deducted",
+			regime: regimeReel,
+			income: rentalIncome{Rents: 12000, Expenses: 3000, Interest: 2000},
+			want:   RentalTax{Eligible: true, TaxableIncome: 7000, IncomeTax: 2100, SocialLevies: 1204, Total: 3304},
+		},
+		{
+			// the interest is covered by the rents, the remaining 10000 of expenses are deducted
+			// from the global income
+			name:   "réel, land deficit",
+			regime: regimeReel,
+			income: rentalIncome{Rents: 6000, Expenses: 12000, Interest: 4000},
+			want:   RentalTax{Eligible: true, TaxableIncome: -10000, IncomeTax: -3000, Total: -3000},
+		},
+		{
+			name:   "réel, land deficit above the ceiling",
+			regime: regimeReel,
+			income: rentalIncome{Rents: 6000, Expenses: 20000, Interest: 1000},
+			want:   RentalTax{Eligible: true, TaxableIncome: -15000, IncomeTax: -3210, Total: -3210},
+		},
+		{
+			// the rents are absorbed by the interest: only the 1000 of other expenses are deducted
+			// from the global income, the deficit coming from the interest is not
+			name:   "réel, deficit from the interest",
+			regime: regimeReel,
+			income: rentalIncome{Rents: 6000, Expenses: 1000, Interest: 8000},
+			want:   RentalTax{Eligible: true, TaxableIncome: -3000, IncomeTax: -300, Total: -300},
+		},
+		{
+			name:   "micro-BIC, 50% allowance",
+			regime: regimeMicroBIC,
+			income: rentalIncome{Rents: 20000},
+			want:   RentalTax{Eligible: true, TaxableIncome: 10000, IncomeTax: 3000, SocialLevies: 1720, Total: 4720},
+		},
+		{
+			name:   "micro-BIC above the ceiling",
+			regime: regimeMicroBIC,
+			income: rentalIncome{Rents: 80000},
+			want:   RentalTax{Eligible: false, TaxableIncome: 40000, IncomeTax: 12000, SocialLevies: 6880, Total: 18880},
+		},
+		{
+			name:   "LMNP réel, partial depreciation",
+			regime: regimeLMNPReel,
+			income: rentalIncome{Rents: 12000, Expenses: 3000, Interest: 2000, Depreciation: 4000},
+			want:   RentalTax{Eligible: true, TaxableIncome: 3000, IncomeTax: 900, SocialLevies: 516, Total: 1416},
+		},
+		{
+			// the depreciation cannot create a deficit
+			name:   "LMNP réel, depreciation above the income",
+			regime: regimeLMNPReel,
+			income: rentalIncome{Rents: 12000, Expenses: 3000, Interest: 2000, Depreciation: 10000},
+			want:   RentalTax{Eligible: true},
+		},
+	}
+	for _, tt := range tests {
+		tt.want.Regime = tt.regime
+		if got := rentalTaxes(tt.regime, tt.income, marginalTaxRate).rounded(); got != tt.want {
+			t.Errorf("%s: rentalTaxes() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnnualDepreciation(t *testing.T) {
+	var (
+		good = Property{Price: 210000, FurnitureValue: 10000, RenovationCost: 30000, FournitureCost: 4000}
+		fees = AcquisitionFees{Total: 16000}
+	)
+	// building (200000 + 16000) * 85% / 30 + works 30000 / 15 + furniture 14000 / 7
+	if got, want := annualDepreciation(good, fees, RentalInvestment{}), 6120.0+2000+2000; got != want {
+		t.Errorf("annualDepreciation() = %.2f, want %.2f", got, want)
+	}
+}
@@ -13,7 +13,18 @@ func runImmo(cmd *cobra.Command, args []string) error {
 }
 
 func init() {
+	ImmoCmd.AddCommand(amortizeCmd)
 	ImmoCmd.AddCommand(analyzeCmd)
+	ImmoCmd.AddCommand(capacityCmd)
+	ImmoCmd.AddCommand(compsCmd)
 	ImmoCmd.AddCommand(evaluateCmd)
+	ImmoCmd.AddCommand(mortgagesCmd)
+	ImmoCmd.AddCommand(projectCmd)
+	ImmoCmd.AddCommand(rankCmd)
+	ImmoCmd.AddCommand(sensitivityCmd)
 	ImmoCmd.AddCommand(showSchemaCmd)
+	ImmoCmd.AddCommand(simulateCmd)
+	ImmoCmd.AddCommand(statsCmd)
+	ImmoCmd.AddCommand(targetPriceCmd)
+	ImmoCmd.AddCommand(validateCmd)
 }
@@ -0,0 +1,54 @@
+package immo
+
+import (
+	"maps"
+	"slices"
+)
+
+// scenario is a good evaluated with a financing plan. Its inputs can be altered to test
+// hypotheses without modifying the configuration.
+type scenario struct {
+	cfg  ImmoConfig
+	good Property
+	plan FinancingPlan
+
+	// transferTaxesFactor scales the rate of the transfer taxes of the good. Zero leaves it
+	// unchanged.
+	transferTaxesFactor float64
+}
+
+// scenariosOf returns the scenarios of the configuration, every good with every financing plan,
+// optionally filtered by good and by financing plan name.
+func scenariosOf(cfg ImmoConfig, goodName, planName string) []scenario {
+	var scenarios []scenario
+	for _, good := range cfg.Goods {
+		if goodName != "" && good.Name != goodName {
+			continue
+		}
+		for _, plan := range cfg.financingPlans() {
+			if planName != "" && plan.Name != planName {
+				continue
+			}
+			scenarios = append(scenarios, scenario{cfg: cfg, good: good, plan: plan})
+		}
+	}
+	return scenarios
+}
+
+// clone returns a copy of the scenario which can be modified without altering the original one.
+func (s scenario) clone() scenario {
+	s.plan.Loans = slices.Clone(s.plan.Loans)
+	s.good.RenovationItems = slices.Clone(s.good.RenovationItems)
+	if s.good.Investment != nil {
+		investment := *s.good.Investment
+		s.good.Investment = &investment
+	}
+	s.cfg.Fees.DepartmentalRates = maps.Clone(s.cfg.Fees.DepartmentalRates)
+	return s
+}
+
+func (s scenario) evaluate() EvaluationResult {
+	ctx := newEvaluationContext(s.cfg, s.plan)
+	ctx.TransferTaxesFactor = s.transferTaxesFactor
+	return evaluate(ctx, s.good)
+}
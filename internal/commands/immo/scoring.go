@@ -0,0 +1,168 @@
+package immo
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Scoring functions of a criterion.
+const (
+	scoringBoolean = "boolean" // 1 when the value is true, 0 otherwise
+	scoringLinear  = "linear"  // from 0 at the worst value to 1 at the best value
+	scoringLevels  = "levels"  // a score for each value, e.g. for the DPE letters
+)
+
+var scoringFunctions = []string{scoringBoolean, scoringLinear, scoringLevels}
+
+// ScoringConfig is the model used to score and rank the goods.
+type ScoringConfig struct {
+	// Criteria are the criteria of the score, each one weighted. The score of a good is the
+	// weighted average of the scores of its criteria, out of 100.
+	Criteria []ScoringCriterion `yaml:"criteria"`
+}
+
+// ScoringCriterion is a criterion of the score of a good.
+type ScoringCriterion struct {
+	// Name is the name of the criterion in the breakdown. Defaults to the field.
+	Name string `yaml:"name"`
+
+	// Field is the value scored by the criterion: a field of the good or of the result of its
+	// evaluation, e.g. good.has_garden or result.contribution, short for
+	// result.new_property_purchase.contribution, or an expression combining them like in the alert
+	// rules, e.g. result.monthly_expenses / good.total_living_space_m2.
+	Field string `yaml:"field"`
+
+	// Weight is the weight of the criterion in the score, relative to the other criteria.
+	Weight float64 `yaml:"weight"`
+
+	// Function is the scoring function: boolean, linear or levels. Defaults to levels when levels
+	// are set, to linear when worst or best is set, and to boolean otherwise.
+	Function string `yaml:"function"`
+
+	// Worst and Best are the values scored 0 and 1 by the linear function. The values beyond are
+	// capped. Worst is greater than best when lower is better, e.g. for the contribution. Text
+	// values are read from their leading number, e.g. 10 for "10 min".
+	Worst float64 `yaml:"worst"`
+	Best  float64 `yaml:"best"`
+
+	// Levels are the scores of each value, between 0 and 1, e.g. {A: 1, B: 0.9, ..., G: 0}. The
+	// other values score 0.
+	Levels map[string]float64 `yaml:"levels"`
+}
+
+func (c ScoringCriterion) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Field
+}
+
+func (c ScoringCriterion) function() string {
+	switch {
+	case c.Function != "":
+		return c.Function
+	case len(c.Levels) > 0:
+		return scoringLevels
+	case c.Worst != 0 || c.Best != 0:
+		return scoringLinear
+	default:
+		return scoringBoolean
+	}
+}
+
+// leadingNumberRe matches the number at the beginning of a text, e.g. "10" in "10 min".
+var leadingNumberRe = regexp.MustCompile(`^\s*(-?[0-9]+(?:[.,][0-9]+)?)`)
+
+// numericValue returns the value as a number, reading the leading number of a text.
+func numericValue(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case string:
+		match := leadingNumberRe.FindStringSubmatch(v)
+		if match == nil {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(strings.Replace(match[1], ",", ".", 1), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// score returns the score of the criterion for the environment of an evaluation, between 0 and 1,
+// with the value scored. A missing value scores 0, except for a boolean where missing means false.
+func (c ScoringCriterion) score(env map[string]any) CriterionScore {
+	result := CriterionScore{Criterion: c.name(), Weight: c.Weight}
+	field, err := parseExpr(c.Field)
+	if err != nil {
+		result.Value = fmt.Sprintf("invalid field: %v", err)
+		result.Missing = true
+		return result
+	}
+	value, err := field.eval(env)
+	if err != nil {
+		result.Value = err.Error()
+		result.Missing = true
+		return result
+	}
+	result.Value = formatValue(value)
+
+	switch c.function() {
+	case scoringBoolean:
+		if truthy(value) {
+			result.Score = 1
+		} else if value == nil {
+			result.Value = formatValue(false)
+		}
+	case scoringLinear:
+		f, ok := numericValue(value)
+		if !ok {
+			result.Missing = true
+			break
+		}
+		if c.Best != c.Worst {
+			result.Score = math.Min(math.Max((f-c.Worst)/(c.Best-c.Worst), 0), 1)
+		}
+	case scoringLevels:
+		if value == nil {
+			result.Missing = true
+			break
+		}
+		result.Score = c.Levels[formatValue(value)]
+	}
+	return result
+}
+
+// scoreGood returns the score of a good with a financing plan, out of 100, with the breakdown of
+// its criteria. The points of a criterion are its share of the score.
+func scoreGood(criteria []ScoringCriterion, good Property, result EvaluationResult) (float64, []CriterionScore) {
+	var (
+		env         = evaluationEnv(good, result)
+		totalWeight float64
+		total       float64
+		breakdown   = make([]CriterionScore, len(criteria))
+	)
+	for _, c := range criteria {
+		totalWeight += c.Weight
+	}
+	for i, c := range criteria {
+		s := c.score(env)
+		if totalWeight > 0 {
+			s.Points = s.Score * c.Weight / totalWeight * 100
+		}
+		total += s.Points
+		s.Score = math.Round(s.Score*1000) / 1000
+		s.Points = math.Round(s.Points*10) / 10
+		breakdown[i] = s
+	}
+	return math.Round(total*10) / 10, breakdown
+}
@@ -0,0 +1,70 @@
+package immo
+
+import "math"
+
+const (
+	// earlyRepaymentPenaltyMonths and earlyRepaymentPenaltyRate cap the early repayment penalty
+	// (indemnités de remboursement anticipé): the lowest of 6 months of interest and 3% of the
+	// remaining capital.
+	earlyRepaymentPenaltyMonths = 6
+	earlyRepaymentPenaltyRate   = 0.03
+)
+
+// earlyRepaymentPenalty returns the maximum penalty that the bank can charge when the remaining
+// capital of the current loan is repaid before its term.
+func earlyRepaymentPenalty(cp CurrentPropertyContext) float64 {
+	return repaymentPenalty(cp.RemainingLoanCapital, cp.LoanInterestRate)
+}
+
+// repaymentPenalty returns the maximum early repayment penalty of a remaining capital borrowed at
+// the given annual rate.
+func repaymentPenalty(capital, annualRate float64) float64 {
+	interest := capital * annualRate / 12 * earlyRepaymentPenaltyMonths
+	return math.Min(interest, capital*earlyRepaymentPenaltyRate)
+}
+
+// computeSale returns the detail of the sale of the current property. The net proceeds are what
+// remains after paying the agency, the remaining loan and its early repayment penalty.
+func computeSale(cp CurrentPropertyContext) Sale {
+	var (
+		agencyFees = cp.EstimatedSalePrice * cp.SaleAgencyFeesRate
+		penalty    = earlyRepaymentPenalty(cp)
+	)
+	return Sale{
+		SalePrice:             cp.EstimatedSalePrice,
+		AgencyFees:            math.Round(agencyFees),
+		RemainingLoanCapital:  math.Round(cp.RemainingLoanCapital),
+		EarlyRepaymentPenalty: math.Round(penalty),
+		NetProceeds:           math.Round(cp.EstimatedSalePrice - agencyFees - cp.RemainingLoanCapital - penalty),
+	}
+}
+
+// compareSellVsKeep compares the scenario where the current property is kept and rented out with
+// the scenario where it is sold, and the net proceeds are injected into the contribution.
+//
+// When the current property is sold, the family loses the rents and stops paying the current
+// mortgage.
+func compareSellVsKeep(ctx EvaluationContext, keep ScenarioSummary, purchaseCost, monthlyRentingIncome, monthlyLoanPayments float64) *SellVsKeep {
+	cp := ctx.CurrentProperty
+	if cp.EstimatedSalePrice == 0 {
+		return nil
+	}
+	var (
+		sale            = computeSale(cp)
+		contribution    = purchaseCost - ctx.Financing.Amount() - sale.NetProceeds
+		monthlyExpenses = keep.MonthlyExpenses + monthlyRentingIncome - cp.MonthlyMortgage
+		sell            = ScenarioSummary{
+			Contribution:    math.Round(contribution),
+			RemainingAssets: math.Round(ctx.Family.TotalAssets - contribution),
+			MonthlyExpenses: math.Round(monthlyExpenses),
+		}
+	)
+	if ctx.Family.MonthlyNetIncome() > 0 {
+		sell.DebtRatio = computeDebtRatio(ctx.Family, 0, monthlyLoanPayments-cp.MonthlyMortgage).rounded().Ratio
+	}
+	return &SellVsKeep{
+		Sale: sale,
+		Keep: keep,
+		Sell: sell,
+	}
+}
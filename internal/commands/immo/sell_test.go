@@ -0,0 +1,62 @@
+package immo
+
+import "testing"
+
+func TestEarlyRepaymentPenalty(t *testing.T) {
+	tests := []struct {
+		rate float64
+		want float64
+	}{
+		// 6 months of interest
+		{rate: 0.02, want: 1000},
+		// capped at 3% of the remaining capital
+		{rate: 0.08, want: 3000},
+	}
+	for _, tt := range tests {
+		cp := CurrentPropertyContext{RemainingLoanCapital: 100000, LoanInterestRate: tt.rate}
+		if got := earlyRepaymentPenalty(cp); got != tt.want {
+			t.Errorf("earlyRepaymentPenalty() at %g = %.2f, want %.2f", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestCompareSellVsKeep(t *testing.T) {
+	ctx := EvaluationContext{
+		Family: FamilyContext{
+			Borrowers:   []Borrower{{Name: "A", MonthlyNetIncome: 6000}},
+			TotalAssets: 100000,
+		},
+		CurrentProperty: CurrentPropertyContext{
+			MonthlyMortgage:      800,
+			EstimatedSalePrice:   300000,
+			SaleAgencyFeesRate:   0.04,
+			RemainingLoanCapital: 100000,
+			LoanInterestRate:     0.02,
+		},
+		Financing: singleLoanPlan(Mortgage{Amount: 300000}),
+	}
+	keep := ScenarioSummary{Contribution: 200000, RemainingAssets: -100000, MonthlyExpenses: 3500, DebtRatio: 0.4}
+
+	got := compareSellVsKeep(ctx, keep, 500000, 1000, 2400)
+	if got == nil {
+		t.Fatal("compareSellVsKeep() = nil")
+	}
+	wantSale := Sale{SalePrice: 300000, AgencyFees: 12000, RemainingLoanCapital: 100000, EarlyRepaymentPenalty: 1000, NetProceeds: 187000}
+	if got.Sale != wantSale {
+		t.Errorf("sale = %+v, want %+v", got.Sale, wantSale)
+	}
+	// the net proceeds lower the contribution, the rents are lost and the current mortgage is
+	// no longer paid
+	wantSell := ScenarioSummary{Contribution: 13000, RemainingAssets: 87000, MonthlyExpenses: 3700, DebtRatio: 0.267}
+	if got.Sell != wantSell {
+		t.Errorf("sell = %+v, want %+v", got.Sell, wantSell)
+	}
+	if got.Keep != keep {
+		t.Errorf("keep = %+v, want %+v", got.Keep, keep)
+	}
+
+	ctx.CurrentProperty.EstimatedSalePrice = 0
+	if got := compareSellVsKeep(ctx, keep, 500000, 1000, 2400); got != nil {
+		t.Errorf("compareSellVsKeep() without sale price = %+v, want nil", got)
+	}
+}
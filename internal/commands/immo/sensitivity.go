@@ -0,0 +1,337 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var sensitivityCmd = &cobra.Command{
+	Use:   "sensitivity",
+	Short: "Vary one input at a time and show how each scenario reacts.",
+	RunE:  runSensitivity,
+}
+
+var (
+	sensitivityOutput    string
+	sensitivityVariation float64
+	sensitivitySteps     int
+	sensitivityGood      string
+	sensitivityBank      string
+)
+
+func init() {
+	sensitivityCmd.Flags().StringVarP(&sensitivityOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	sensitivityCmd.Flags().Float64Var(&sensitivityVariation, "variation", 0.1, "Largest relative variation applied to each input, e.g. 0.1 for -10% and +10%")
+	sensitivityCmd.Flags().IntVar(&sensitivitySteps, "steps", 2, "Number of variations evaluated on each side of the base, e.g. 2 for -10%, -5%, +5% and +10%")
+	sensitivityCmd.Flags().StringVar(&sensitivityGood, "good", "", "Only analyze the good with this name")
+	sensitivityCmd.Flags().StringVar(&sensitivityBank, "bank", "", "Only analyze the mortgage of this bank, or the financing plan with this name")
+}
+
+// sensitivityInput is an input of a scenario which can be varied. The factor is applied to the
+// input, e.g. 1.1 for +10%.
+type sensitivityInput struct {
+	name  string
+	apply func(s *scenario, factor float64)
+}
+
+var sensitivityInputs = []sensitivityInput{
+	{name: "price", apply: func(s *scenario, factor float64) {
+		s.good.Price *= factor
+	}},
+	{name: "interest_rate", apply: func(s *scenario, factor float64) {
+		for i := range s.plan.Loans {
+			loan := &s.plan.Loans[i]
+			if !loan.HasTerms() || loan.MonthlyCost == 0 {
+				loan.InterestRate *= factor
+				continue
+			}
+			// keep the gap between the typed and the computed monthly costs, so that the
+			// mismatch alert is unchanged
+			ratio := loan.MonthlyCost / loan.ComputedMonthlyCost()
+			loan.InterestRate *= factor
+			loan.MonthlyCost = loan.ComputedMonthlyCost() * ratio
+		}
+	}},
+	{name: "renovation_cost", apply: func(s *scenario, factor float64) {
+		s.good.RenovationCost *= factor
+		for i := range s.good.RenovationItems {
+			s.good.RenovationItems[i].Cost *= factor
+		}
+	}},
+	{name: "rent", apply: func(s *scenario, factor float64) {
+		s.cfg.CurrentProperty.MonthlyIncome *= factor
+		if s.good.Investment != nil {
+			s.good.Investment.MonthlyRent *= factor
+		}
+	}},
+	{name: "notary_rate", apply: func(s *scenario, factor float64) {
+		// the transfer taxes are most of the acquisition fees, whether the DMTO of an existing
+		// good or the reduced rate of a new build
+		s.transferTaxesFactor = factor
+	}},
+	{name: "expenses", apply: func(s *scenario, factor float64) {
+		s.cfg.Family.MonthlyExpenses *= factor
+	}},
+}
+
+// SensitivityReport is the sensitivity of each scenario to its inputs.
+type SensitivityReport struct {
+	Variation             float64               `yaml:"variation" json:"variation"`
+	Steps                 int                   `yaml:"steps" json:"steps"` // on each side of the base
+	ContributionThreshold float64               `yaml:"contribution_threshold" json:"contribution_threshold"`
+	Scenarios             []ScenarioSensitivity `yaml:"scenarios" json:"scenarios"`
+}
+
+// ScenarioSensitivity is the sensitivity of a scenario, with its inputs sorted by decreasing swing
+// of the contribution, then of the monthly expenses.
+type ScenarioSensitivity struct {
+	Good   string             `yaml:"good" json:"good"`
+	Bank   string             `yaml:"bank" json:"bank"`
+	Base   SensitivityPoint   `yaml:"base" json:"base"`
+	Inputs []InputSensitivity `yaml:"inputs" json:"inputs"`
+}
+
+// SensitivityPoint is the outcome of a scenario for a value of the inputs.
+type SensitivityPoint struct {
+	Contribution    float64 `yaml:"contribution" json:"contribution"`
+	MonthlyExpenses float64 `yaml:"monthly_expenses" json:"monthly_expenses"`
+	Alerts          int     `yaml:"alerts" json:"alerts"`
+}
+
+// InputSensitivity is the outcome of a scenario when an input varies across the range, from the
+// largest decrease to the largest increase.
+type InputSensitivity struct {
+	Input string            `yaml:"input" json:"input"`
+	Steps []SensitivityStep `yaml:"steps" json:"steps"` // sorted by increasing variation
+	Swing float64           `yaml:"swing" json:"swing"` // difference between the highest and the lowest contribution
+
+	// TipsThreshold lists the variations, e.g. -10%, putting the contribution on the other side of
+	// the threshold than the base scenario.
+	TipsThreshold []string `yaml:"tips_threshold,omitempty" json:"tips_threshold,omitempty"`
+}
+
+// SensitivityStep is the outcome of a scenario when an input varies by a given ratio.
+type SensitivityStep struct {
+	Variation        float64 `yaml:"variation" json:"variation"` // e.g. -0.1 for -10%
+	SensitivityPoint `yaml:",inline"`
+}
+
+func runSensitivity(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(sensitivityOutput)
+	if err != nil {
+		return err
+	}
+	if sensitivityVariation <= 0 || sensitivityVariation >= 1 {
+		return fmt.Errorf("the variation must be between 0 and 1, got %g", sensitivityVariation)
+	}
+	if sensitivitySteps < 1 {
+		return fmt.Errorf("the steps must be at least 1, got %d", sensitivitySteps)
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+
+	report := SensitivityReport{
+		Variation:             sensitivityVariation,
+		Steps:                 sensitivitySteps,
+		ContributionThreshold: cfg.Family.ContributionThreshold,
+	}
+	variations := sensitivityVariations(sensitivityVariation, sensitivitySteps)
+	for _, s := range scenariosOf(cfg, sensitivityGood, sensitivityBank) {
+		report.Scenarios = append(report.Scenarios, analyzeSensitivity(s, variations))
+	}
+	if len(report.Scenarios) == 0 {
+		return errors.New("no scenario to analyze")
+	}
+
+	switch format {
+	case outputYAML, outputJSON:
+		return writeDocument(os.Stdout, format, report)
+	case outputCSV:
+		return writeTable(os.Stdout, format, report.table())
+	default:
+		for i, s := range report.Scenarios {
+			if format == outputMarkdown {
+				fmt.Printf("## %s - %s\n\n", s.Good, s.Bank)
+			} else {
+				fmt.Printf("%d. Sensitivity of %q with %s (±%.0f%%)\n", i+1, s.Good, s.Bank, report.Variation*100)
+				fmt.Println("==========")
+			}
+			fmt.Printf("Base: contribution %.0f, monthly expenses %.0f, %d alert(s)\n\n", s.Base.Contribution, s.Base.MonthlyExpenses, s.Base.Alerts)
+			if err := writeTable(os.Stdout, format, s.table()); err != nil {
+				return err
+			}
+			fmt.Println()
+		}
+		return nil
+	}
+}
+
+// sensitivityVariations returns the variations applied to each input: the given number of steps
+// evenly spread on each side of the base, up to the largest variation. For example, 2 steps of a
+// variation of 10% give -10%, -5%, +5% and +10%.
+func sensitivityVariations(variation float64, steps int) []float64 {
+	variations := make([]float64, 0, 2*steps)
+	for i := -steps; i <= steps; i++ {
+		if i != 0 {
+			variations = append(variations, variation*float64(i)/float64(steps))
+		}
+	}
+	return variations
+}
+
+// analyzeSensitivity evaluates the scenario with each input varied by each variation, the other
+// inputs being unchanged.
+func analyzeSensitivity(s scenario, variations []float64) ScenarioSensitivity {
+	var (
+		threshold = s.cfg.Family.ContributionThreshold
+		base      = sensitivityPointOf(s.evaluate())
+		result    = ScenarioSensitivity{Good: s.good.Name, Bank: s.plan.Name, Base: base}
+	)
+	for _, input := range sensitivityInputs {
+		sensitivity := InputSensitivity{Input: input.name}
+		for _, variation := range variations {
+			varied := s.clone()
+			input.apply(&varied, 1+variation)
+			step := SensitivityStep{Variation: variation, SensitivityPoint: sensitivityPointOf(varied.evaluate())}
+			sensitivity.Steps = append(sensitivity.Steps, step)
+			if (step.Contribution > threshold) != (base.Contribution > threshold) {
+				sensitivity.TipsThreshold = append(sensitivity.TipsThreshold, formatVariation(variation))
+			}
+		}
+		sensitivity.Swing = sensitivity.swing(func(p SensitivityPoint) float64 { return p.Contribution })
+		result.Inputs = append(result.Inputs, sensitivity)
+	}
+	sort.SliceStable(result.Inputs, func(i, j int) bool {
+		a, b := result.Inputs[i], result.Inputs[j]
+		if a.Swing != b.Swing {
+			return a.Swing > b.Swing
+		}
+		return a.monthlyExpensesSwing() > b.monthlyExpensesSwing()
+	})
+	return result
+}
+
+// swing returns the difference between the highest and the lowest value of the steps.
+func (s InputSensitivity) swing(value func(SensitivityPoint) float64) float64 {
+	lowest, highest := math.Inf(1), math.Inf(-1)
+	for _, step := range s.Steps {
+		lowest = math.Min(lowest, value(step.SensitivityPoint))
+		highest = math.Max(highest, value(step.SensitivityPoint))
+	}
+	return highest - lowest
+}
+
+func (s InputSensitivity) monthlyExpensesSwing() float64 {
+	return s.swing(func(p SensitivityPoint) float64 { return p.MonthlyExpenses })
+}
+
+// low returns the outcome of the largest decrease of the input.
+func (s InputSensitivity) low() SensitivityPoint {
+	return s.Steps[0].SensitivityPoint
+}
+
+// high returns the outcome of the largest increase of the input.
+func (s InputSensitivity) high() SensitivityPoint {
+	return s.Steps[len(s.Steps)-1].SensitivityPoint
+}
+
+// formatVariation formats a variation as a signed percentage, e.g. -10% or +2.5%.
+func formatVariation(variation float64) string {
+	return fmt.Sprintf("%+g%%", math.Round(variation*1000)/10)
+}
+
+func sensitivityPointOf(result EvaluationResult) SensitivityPoint {
+	return SensitivityPoint{
+		Contribution:    result.NewPropertyPurchaseCost.Contribution,
+		MonthlyExpenses: result.NewPropertyOperationalCost.MonthlyExpenses,
+		Alerts:          len(result.Alerts),
+	}
+}
+
+// tornadoWidth is the width of the bar of the largest swing in the tornado table.
+const tornadoWidth = 20
+
+// sensitivityHeaders returns the headers of the sensitivity of an input, with a column of
+// contribution per variation.
+func sensitivityHeaders(variations []float64) []string {
+	headers := []string{"input"}
+	for _, v := range variations {
+		headers = append(headers, "contribution_"+formatVariation(v))
+	}
+	return append(headers,
+		"swing",
+		"monthly_expenses_low",
+		"monthly_expenses_high",
+		"alerts_low",
+		"alerts_high",
+		"tips_threshold",
+	)
+}
+
+func (s InputSensitivity) cells() []string {
+	cells := []string{s.Input}
+	for _, step := range s.Steps {
+		cells = append(cells, formatAmount(step.Contribution))
+	}
+	return append(cells,
+		formatAmount(s.Swing),
+		formatAmount(s.low().MonthlyExpenses),
+		formatAmount(s.high().MonthlyExpenses),
+		fmt.Sprint(s.low().Alerts),
+		fmt.Sprint(s.high().Alerts),
+		strings.Join(s.TipsThreshold, ", "),
+	)
+}
+
+// variations returns the variations evaluated for the inputs of the scenario.
+func (s ScenarioSensitivity) variations() []float64 {
+	if len(s.Inputs) == 0 {
+		return nil
+	}
+	variations := make([]float64, len(s.Inputs[0].Steps))
+	for i, step := range s.Inputs[0].Steps {
+		variations[i] = step.Variation
+	}
+	return variations
+}
+
+// table returns the tornado table of the scenario: the inputs sorted by decreasing swing, with a
+// bar proportional to the swing of the contribution.
+func (s ScenarioSensitivity) table() table {
+	t := table{headers: append(sensitivityHeaders(s.variations()), "tornado")}
+	var largest float64
+	if len(s.Inputs) > 0 {
+		largest = s.Inputs[0].Swing
+	}
+	for _, input := range s.Inputs {
+		var bar string
+		if largest > 0 {
+			bar = strings.Repeat("#", int(math.Round(input.Swing/largest*tornadoWidth)))
+		}
+		t.append(append(input.cells(), bar)...)
+	}
+	return t
+}
+
+// table returns the sensitivity of all the scenarios in a single table.
+func (r SensitivityReport) table() table {
+	var variations []float64
+	if len(r.Scenarios) > 0 {
+		variations = r.Scenarios[0].variations()
+	}
+	t := table{headers: append([]string{"good", "bank"}, sensitivityHeaders(variations)...)}
+	for _, s := range r.Scenarios {
+		for _, input := range s.Inputs {
+			t.append(append([]string{s.Good, s.Bank}, input.cells()...)...)
+		}
+	}
+	return t
+}
@@ -0,0 +1,131 @@
+package immo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestSensitivityVariations(t *testing.T) {
+	tests := []struct {
+		variation float64
+		steps     int
+		want      []float64
+	}{
+		{variation: 0.1, steps: 1, want: []float64{-0.1, 0.1}},
+		{variation: 0.1, steps: 2, want: []float64{-0.1, -0.05, 0.05, 0.1}},
+		{variation: 0.3, steps: 3, want: []float64{-0.3, -0.2, -0.1, 0.1, 0.2, 0.3}},
+	}
+	for _, tt := range tests {
+		got := sensitivityVariations(tt.variation, tt.steps)
+		if len(got) != len(tt.want) {
+			t.Fatalf("sensitivityVariations(%g, %d) = %v, want %v", tt.variation, tt.steps, got, tt.want)
+		}
+		for i := range got {
+			if math.Abs(got[i]-tt.want[i]) > 1e-9 {
+				t.Errorf("sensitivityVariations(%g, %d) = %v, want %v", tt.variation, tt.steps, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestFormatVariation(t *testing.T) {
+	for variation, want := range map[float64]string{-0.1: "-10%", 0.05: "+5%", 0.025: "+2.5%", 0.1 / 3: "+3.3%"} {
+		if got := formatVariation(variation); got != want {
+			t.Errorf("formatVariation(%g) = %q, want %q", variation, got, want)
+		}
+	}
+}
+
+// sensitivityScenario is a purchase of 350000 with a loan of 300000, whose contribution of about
+// 75700 is just below the threshold of 80000.
+func sensitivityScenario(newBuild bool) scenario {
+	cfg := ImmoConfig{
+		Family: FamilyContext{
+			TotalAssets:           120000,
+			ContributionThreshold: 80000,
+			MonthlyExpenses:       3000,
+			Borrowers:             []Borrower{{Name: "A", MonthlyNetIncome: 6000}},
+		},
+		EstimatedMortgages: []Mortgage{{Bank: "BNP", Amount: 300000, InterestRate: 0.035, Years: 25}},
+		Goods: []Property{{
+			Name:                    "maison-a",
+			Price:                   350000,
+			ZipCode:                 "92160",
+			NewBuild:                newBuild,
+			TotalLivingSpaceM2:      100,
+			EnergyPerformanceRating: "D",
+		}},
+	}
+	return scenariosOf(cfg, "", "")[0]
+}
+
+func TestAnalyzeSensitivity(t *testing.T) {
+	var (
+		variations  = []float64{-0.1, -0.05, 0.05, 0.1}
+		sensitivity = analyzeSensitivity(sensitivityScenario(false), variations)
+		inputs      = make(map[string]InputSensitivity)
+	)
+	for _, input := range sensitivity.Inputs {
+		inputs[input.Input] = input
+	}
+	if got := sensitivity.variations(); !reflect.DeepEqual(got, variations) {
+		t.Errorf("variations = %v, want %v", got, variations)
+	}
+	if len(inputs) != len(sensitivityInputs) {
+		t.Fatalf("%d inputs, want %d", len(inputs), len(sensitivityInputs))
+	}
+
+	// the price moves the contribution the most, and its increases tip it over the threshold
+	price := sensitivity.Inputs[0]
+	if price.Input != "price" {
+		t.Errorf("largest swing = %s, want price", price.Input)
+	}
+	for i := 1; i < len(price.Steps); i++ {
+		if price.Steps[i].Contribution <= price.Steps[i-1].Contribution {
+			t.Errorf("contributions of the price = %+v, want them increasing", price.Steps)
+			break
+		}
+	}
+	if got, want := price.Swing, price.high().Contribution-price.low().Contribution; got != want {
+		t.Errorf("swing of the price = %.0f, want %.0f", got, want)
+	}
+	if want := []string{"+5%", "+10%"}; !reflect.DeepEqual(price.TipsThreshold, want) {
+		t.Errorf("price tips the threshold at %q, want %q", price.TipsThreshold, want)
+	}
+
+	// the expenses only move the monthly expenses
+	expenses := inputs["expenses"]
+	if expenses.Swing != 0 {
+		t.Errorf("swing of the expenses = %.0f, want 0", expenses.Swing)
+	}
+	if got := expenses.monthlyExpensesSwing(); got != 600 {
+		t.Errorf("swing of the monthly expenses = %.0f, want 600", got)
+	}
+	if got := sensitivity.Base.Contribution; got < 75000 || got > 80000 {
+		t.Errorf("base contribution = %.0f, want just below the threshold", got)
+	}
+}
+
+func TestAnalyzeSensitivityNotaryRate(t *testing.T) {
+	variations := []float64{-0.1, 0.1}
+	for _, newBuild := range []bool{false, true} {
+		var (
+			s        = sensitivityScenario(newBuild)
+			fees     = computeAcquisitionFees(s.good, FeesConfig{})
+			notary   InputSensitivity
+			analyzed = analyzeSensitivity(s, variations)
+		)
+		for _, input := range analyzed.Inputs {
+			if input.Input == "notary_rate" {
+				notary = input
+			}
+		}
+		// the transfer taxes which apply to the good are scaled, the reduced rate of a new build
+		// included
+		if got, want := notary.Swing, math.Round(fees.TransferTaxes*0.2); math.Abs(got-want) > 1 {
+			t.Errorf("new build %t: swing of the notary rate = %.0f, want %.0f", newBuild, got, want)
+		}
+	}
+}
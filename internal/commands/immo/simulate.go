@@ -0,0 +1,380 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate",
+	Short: "Simulate random outcomes of each scenario and report their percentiles.",
+	RunE:  runSimulate,
+}
+
+var (
+	simulateOutput    string
+	simulateRuns      int
+	simulateYears     int
+	simulateSeed      uint64
+	simulateStartYear int
+	simulateGood      string
+	simulateBank      string
+)
+
+func init() {
+	simulateCmd.Flags().StringVarP(&simulateOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	simulateCmd.Flags().IntVar(&simulateRuns, "runs", 5000, "Number of simulations per scenario")
+	simulateCmd.Flags().IntVar(&simulateYears, "years", 20, "Horizon of the simulation in years")
+	simulateCmd.Flags().Uint64Var(&simulateSeed, "seed", 1, "Seed of the random generator, the same seed gives the same results")
+	simulateCmd.Flags().IntVar(&simulateStartYear, "start-year", time.Now().Year(), "Calendar year of the purchase")
+	simulateCmd.Flags().StringVar(&simulateGood, "good", "", "Only simulate the good with this name")
+	simulateCmd.Flags().StringVar(&simulateBank, "bank", "", "Only simulate the mortgage of this bank, or the financing plan with this name")
+}
+
+// Default distributions of the simulation.
+const (
+	defaultAppreciationStdDev      = 0.04
+	defaultInflationStdDev         = 0.01
+	defaultRenegotiationYear       = 7
+	defaultRateChangeStdDev        = 0.01
+	defaultRenegotiationThreshold  = 0.007
+	defaultRenegotiationFeesRate   = 0.01
+	defaultRenovationOverrunMean   = 0.10
+	defaultRenovationOverrunStdDev = 0.15
+	defaultVacancyMean             = 0.04
+	defaultVacancyStdDev           = 0.04
+)
+
+// SimulationConfig configures the distributions of the simulation. The means of the yearly rates
+// are the assumptions of the projections, all the distributions are normal.
+type SimulationConfig struct {
+	// AppreciationStdDev is the standard deviation of the yearly appreciation of the property.
+	AppreciationStdDev float64 `yaml:"appreciation_stddev"`
+
+	// InflationStdDev is the standard deviation of the yearly inflation. The rents follow the
+	// inflation.
+	InflationStdDev float64 `yaml:"inflation_stddev"`
+
+	// RenegotiationYear is the year when the main loan can be renegotiated, if the rates went
+	// down. The other lines, e.g. a PTZ, keep their terms.
+	RenegotiationYear int `yaml:"renegotiation_year"`
+
+	// RateChangeStdDev is the standard deviation of the change of the market rate at the
+	// renegotiation year, from the rate of the main loan. The rate never goes below zero.
+	RateChangeStdDev float64 `yaml:"rate_change_stddev"`
+
+	// RenegotiationThreshold is the minimum decrease of the rate making a renegotiation worth it,
+	// e.g. 0.007 for 0.7 point.
+	RenegotiationThreshold float64 `yaml:"renegotiation_threshold"`
+
+	// RenegotiationFeesRate is the cost of the new loan, as a share of the remaining capital: its
+	// guarantee and application fees, e.g. 0.01 for 1%. With the early repayment penalty, they
+	// are financed by the new loan, which is only taken when it costs less than the old one.
+	RenegotiationFeesRate float64 `yaml:"renegotiation_fees_rate"`
+
+	// RenovationOverrunMean and RenovationOverrunStdDev describe the overrun of the renovation
+	// cost, e.g. 0.1 for 10% more than planned. The overrun is never negative.
+	RenovationOverrunMean   float64 `yaml:"renovation_overrun_mean"`
+	RenovationOverrunStdDev float64 `yaml:"renovation_overrun_stddev"`
+
+	// VacancyMean and VacancyStdDev describe the yearly share of time without tenant.
+	VacancyMean   float64 `yaml:"vacancy_mean"`
+	VacancyStdDev float64 `yaml:"vacancy_stddev"`
+}
+
+// orDefault returns the value, or the default value when it is not set.
+func orDefault[T int | float64](value, defaultValue T) T {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// withDefaults returns the configuration where the missing values are replaced by the defaults.
+func (c SimulationConfig) withDefaults() SimulationConfig {
+	return SimulationConfig{
+		AppreciationStdDev:      orDefault(c.AppreciationStdDev, defaultAppreciationStdDev),
+		InflationStdDev:         orDefault(c.InflationStdDev, defaultInflationStdDev),
+		RenegotiationYear:       orDefault(c.RenegotiationYear, defaultRenegotiationYear),
+		RateChangeStdDev:        orDefault(c.RateChangeStdDev, defaultRateChangeStdDev),
+		RenegotiationThreshold:  orDefault(c.RenegotiationThreshold, defaultRenegotiationThreshold),
+		RenegotiationFeesRate:   orDefault(c.RenegotiationFeesRate, defaultRenegotiationFeesRate),
+		RenovationOverrunMean:   orDefault(c.RenovationOverrunMean, defaultRenovationOverrunMean),
+		RenovationOverrunStdDev: orDefault(c.RenovationOverrunStdDev, defaultRenovationOverrunStdDev),
+		VacancyMean:             orDefault(c.VacancyMean, defaultVacancyMean),
+		VacancyStdDev:           orDefault(c.VacancyStdDev, defaultVacancyStdDev),
+	}
+}
+
+// SimulationReport is the structured document of the simulations of all scenarios.
+type SimulationReport struct {
+	Seed        uint64       `yaml:"seed" json:"seed"`
+	Runs        int          `yaml:"runs" json:"runs"`
+	Horizon     int          `yaml:"horizon" json:"horizon"`
+	Simulations []Simulation `yaml:"simulations" json:"simulations"`
+}
+
+// Simulation is the distribution of the outcomes of a scenario at the end of the horizon.
+type Simulation struct {
+	Good          string      `yaml:"good" json:"good"`
+	Bank          string      `yaml:"bank" json:"bank"`
+	NetWorth      Percentiles `yaml:"net_worth" json:"net_worth"`
+	MonthlyBurden Percentiles `yaml:"monthly_burden" json:"monthly_burden"` // living expenses and loans, minus rents
+
+	// RenegotiationRate is the share of the runs where the main loan was renegotiated.
+	RenegotiationRate float64 `yaml:"renegotiation_rate" json:"renegotiation_rate"`
+
+	// NegativeAssetsRate is the share of the runs where the assets went below zero during the
+	// horizon.
+	NegativeAssetsRate float64 `yaml:"negative_assets_rate" json:"negative_assets_rate"`
+}
+
+// Percentiles are the 10th, 50th (median) and 90th percentiles of a distribution.
+type Percentiles struct {
+	P10 float64 `yaml:"p10" json:"p10"`
+	P50 float64 `yaml:"p50" json:"p50"`
+	P90 float64 `yaml:"p90" json:"p90"`
+}
+
+func runSimulate(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(simulateOutput)
+	if err != nil {
+		return err
+	}
+	if simulateRuns < 1 || simulateRuns > 100000 {
+		return fmt.Errorf("the number of runs must be between 1 and 100000, got %d", simulateRuns)
+	}
+	if simulateYears < 1 || simulateYears > 50 {
+		return fmt.Errorf("the horizon must be between 1 and 50 years, got %d", simulateYears)
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+
+	report := SimulationReport{Seed: simulateSeed, Runs: simulateRuns, Horizon: simulateYears}
+	for _, s := range scenariosOf(cfg, simulateGood, simulateBank) {
+		if !s.plan.HasTerms() {
+			fmt.Fprintf(os.Stderr, "Skipping financing %s: interest rate or duration is missing\n", s.plan.Name)
+			continue
+		}
+		report.Simulations = append(report.Simulations, simulate(s, simulationInput{
+			seed:      simulateSeed,
+			runs:      simulateRuns,
+			years:     simulateYears,
+			startYear: simulateStartYear,
+		}))
+	}
+	if len(report.Simulations) == 0 {
+		return errors.New("no scenario to simulate")
+	}
+
+	if format.isDocument() {
+		return writeDocument(os.Stdout, format, report)
+	}
+	return writeTable(os.Stdout, format, report.table())
+}
+
+// simulationInput is the parameters of the simulation of a scenario.
+type simulationInput struct {
+	seed      uint64
+	runs      int
+	years     int
+	startYear int
+}
+
+// simulate runs the projection of the scenario many times, with random assumptions drawn for each
+// year. The random generator of a scenario only depends on the seed and on the names of the good
+// and the financing plan, so that the results do not depend on the other scenarios.
+func simulate(s scenario, in simulationInput) Simulation {
+	var (
+		dist       = s.cfg.Simulation.withDefaults()
+		rng        = rand.New(rand.NewPCG(in.seed, scenarioHash(s)))
+		result     = s.evaluate()
+		plan       = result.financing // with the eco-PTZ of the renovation, if borrowed
+		evaluation = Evaluation{
+			Good:      s.good.Name,
+			Bank:      s.plan.Name,
+			Result:    result,
+			good:      s.good,
+			financing: plan,
+		}
+		lines          = plan.LineSchedules()
+		main           = plan.mainLoanIndex()
+		schedule       = combineSchedules(lines)
+		renovationCost = evaluation.Result.NewPropertyPurchaseCost.RenovationCost
+		netWorths      = make([]float64, in.runs)
+		burdens        = make([]float64, in.runs)
+		renegotiated   int
+		negativeAssets int
+	)
+	for run := range in.runs {
+		var (
+			years       = make([]yearAssumptions, in.years)
+			a           = s.cfg.Assumptions
+			overrun     = math.Max(rng.NormFloat64()*dist.RenovationOverrunStdDev+dist.RenovationOverrunMean, 0)
+			runSchedule = schedule
+			e           = evaluation
+		)
+		for i := range years {
+			inflation := rng.NormFloat64()*dist.InflationStdDev + a.InflationRate
+			years[i] = yearAssumptions{
+				Appreciation: rng.NormFloat64()*dist.AppreciationStdDev + a.AppreciationRate,
+				Inflation:    inflation,
+				RentIncrease: a.RentIncreaseRate + inflation - a.InflationRate,
+				IncomeGrowth: a.IncomeGrowthRate,
+				AssetReturn:  a.AssetReturnRate,
+				Vacancy:      math.Min(math.Max(rng.NormFloat64()*dist.VacancyStdDev+dist.VacancyMean, 0), 1),
+			}
+		}
+
+		// the overrun of the renovation is paid with the assets
+		e.Result.NewPropertyPurchaseCost.RemainingAssets -= renovationCost * overrun
+
+		// the main loan is renegotiated when the market rate went down enough to pay for the
+		// penalty and the fees
+		loan := plan.Loans[main]
+		rate := math.Max(rng.NormFloat64()*dist.RateChangeStdDev+loan.InterestRate, 0)
+		if month := dist.RenegotiationYear * 12; month < len(lines[main]) && loan.InterestRate-rate >= dist.RenegotiationThreshold {
+			if rows, ok := renegotiate(loan, lines[main], month, rate, dist.RenegotiationFeesRate); ok {
+				runLines := slices.Clone(lines)
+				runLines[main] = rows
+				runSchedule = combineSchedules(runLines)
+				renegotiated++
+			}
+		}
+
+		projection := project(projectionInput{
+			evaluation: e,
+			family:     s.cfg.Family,
+			schedule:   runSchedule,
+			startYear:  in.startYear,
+			years:      years,
+		})
+		last := projection.Years[len(projection.Years)-1]
+		netWorths[run] = last.NetWorth
+		burdens[run] = (last.LivingExpenses + last.MortgagePayments - last.RentalIncome) / 12
+		for _, y := range projection.Years {
+			if y.Assets < 0 {
+				negativeAssets++
+				break
+			}
+		}
+	}
+	return Simulation{
+		Good:               s.good.Name,
+		Bank:               s.plan.Name,
+		NetWorth:           percentilesOf(netWorths),
+		MonthlyBurden:      percentilesOf(burdens),
+		RenegotiationRate:  math.Round(float64(renegotiated)/float64(in.runs)*1000) / 1000,
+		NegativeAssetsRate: math.Round(float64(negativeAssets)/float64(in.runs)*1000) / 1000,
+	}
+}
+
+// scenarioHash returns a hash of the names of the good and the financing plan of a scenario.
+func scenarioHash(s scenario) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s.good.Name))
+	h.Write([]byte{0})
+	h.Write([]byte(s.plan.Name))
+	return h.Sum64()
+}
+
+// renegotiate returns the schedule of the loan where, from the given month (0-based), the
+// remaining capital is repaid by a new loan at the given rate over the same remaining duration.
+// The new loan also finances the early repayment penalty and its fees. It returns false when the
+// new loan would cost more than the remaining payments of the old one. The insurance is unchanged.
+func renegotiate(loan Mortgage, rows []AmortizationRow, month int, rate, feesRate float64) ([]AmortizationRow, bool) {
+	var (
+		result    = make([]AmortizationRow, len(rows))
+		remaining = rows[month-1].RemainingCapital
+		penalty   = repaymentPenalty(remaining, loan.InterestRate)
+		capital   = remaining + penalty + remaining*feesRate
+		payment   = annuity(capital, rate, len(rows)-month)
+		oldCost   float64
+	)
+	for _, row := range rows[month:] {
+		oldCost += row.Payment
+	}
+	if payment*float64(len(rows)-month) >= oldCost {
+		return nil, false
+	}
+	copy(result, rows[:month])
+	for i := month; i < len(rows); i++ {
+		interest := capital * rate / 12
+		principal := payment - interest
+		if i == len(rows)-1 {
+			principal = capital
+		}
+		capital -= principal
+		result[i] = AmortizationRow{
+			Month:            rows[i].Month,
+			Payment:          principal + interest,
+			Principal:        principal,
+			Interest:         interest,
+			Insurance:        rows[i].Insurance,
+			RemainingCapital: math.Max(capital, 0),
+		}
+	}
+	return result, true
+}
+
+// percentilesOf returns the percentiles of the values, interpolated linearly between the closest
+// ranks. The values are sorted in place.
+func percentilesOf(values []float64) Percentiles {
+	sort.Float64s(values)
+	return Percentiles{
+		P10: math.Round(percentile(values, 0.1)),
+		P50: math.Round(percentile(values, 0.5)),
+		P90: math.Round(percentile(values, 0.9)),
+	}
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func (r SimulationReport) table() table {
+	t := table{headers: []string{
+		"good",
+		"bank",
+		"net_worth_p10",
+		"net_worth_p50",
+		"net_worth_p90",
+		"monthly_burden_p10",
+		"monthly_burden_p50",
+		"monthly_burden_p90",
+		"renegotiation_rate",
+		"negative_assets_rate",
+	}}
+	for _, s := range r.Simulations {
+		t.append(
+			s.Good,
+			s.Bank,
+			formatAmount(s.NetWorth.P10),
+			formatAmount(s.NetWorth.P50),
+			formatAmount(s.NetWorth.P90),
+			formatAmount(s.MonthlyBurden.P10),
+			formatAmount(s.MonthlyBurden.P50),
+			formatAmount(s.MonthlyBurden.P90),
+			fmt.Sprintf("%.1f%%", s.RenegotiationRate*100),
+			fmt.Sprintf("%.1f%%", s.NegativeAssetsRate*100),
+		)
+	}
+	return t
+}
@@ -0,0 +1,95 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func simulationScenario() scenario {
+	return scenario{
+		cfg: ImmoConfig{
+			Family: FamilyContext{
+				Borrowers:             []Borrower{{Name: "A", MonthlyNetIncome: 4000}, {Name: "B", MonthlyNetIncome: 3500}},
+				MonthlyExpenses:       3000,
+				TotalAssets:           120000,
+				ContributionThreshold: 100000,
+			},
+			Assumptions: Assumptions{
+				AppreciationRate: 0.02,
+				InflationRate:    0.02,
+				AssetReturnRate:  0.03,
+			},
+		},
+		good: Property{
+			Name:                    "house",
+			Price:                   400000,
+			TotalLivingSpaceM2:      100,
+			LivingSpaceLoiCarrezM2:  95,
+			RoomCount:               5,
+			Type:                    "house",
+			ZipCode:                 "92160",
+			EnergyPerformanceRating: "D",
+			RenovationCost:          20000,
+		},
+		plan: FinancingPlan{
+			Name: "main+ptz",
+			Loans: []Mortgage{
+				{Bank: "main", Kind: loanKindMain, Amount: 300000, InterestRate: 0.04, Years: 25, Insurance: 50},
+				{Bank: "ptz", Kind: loanKindPTZ, Amount: 40000, Years: 20},
+			},
+		},
+	}
+}
+
+func TestSimulateSeed(t *testing.T) {
+	var (
+		s  = simulationScenario()
+		in = simulationInput{seed: 42, runs: 500, years: 15, startYear: 2026}
+	)
+	first, second := simulate(s, in), simulate(s, in)
+	if first != second {
+		t.Errorf("same seed, different results:\n%+v\n%+v", first, second)
+	}
+
+	in.seed = 43
+	if other := simulate(s, in); other.NetWorth == first.NetWorth && other.MonthlyBurden == first.MonthlyBurden {
+		t.Errorf("different seeds, same percentiles: %+v", other)
+	}
+	if first.RenegotiationRate <= 0 || first.RenegotiationRate >= 1 {
+		t.Errorf("renegotiation rate = %.3f, want some renegotiations at 4%%", first.RenegotiationRate)
+	}
+}
+
+func TestRenegotiate(t *testing.T) {
+	var (
+		loan      = Mortgage{Amount: 200000, InterestRate: 0.04, Years: 20}
+		rows      = loan.Schedule()
+		month     = 84
+		remaining = rows[month-1].RemainingCapital
+	)
+
+	rows2, ok := renegotiate(loan, rows, month, 0.02, 0.01)
+	if !ok {
+		t.Fatal("renegotiation from 4% to 2% rejected")
+	}
+	// the penalty is 6 months of interest at 4%, i.e. 2% of the remaining capital, and the fees
+	// are 1%: the new loan is 103% of the remaining capital
+	if got, want := rows2[month].Interest, remaining*1.03*0.02/12; math.Abs(got-want) > 0.01 {
+		t.Errorf("first interest of the new loan = %.2f, want %.2f", got, want)
+	}
+	if rows2[month-1] != rows[month-1] {
+		t.Errorf("month %d changed by the renegotiation", month)
+	}
+	if got := rows2[len(rows2)-1].RemainingCapital; got > 0.005 {
+		t.Errorf("remaining capital at the end = %.2f, want 0", got)
+	}
+
+	// 0.3 point does not pay for the 3% of penalty and fees
+	if _, ok := renegotiate(loan, rows, month, 0.037, 0.01); ok {
+		t.Error("renegotiation from 4% to 3.7% accepted, want rejected")
+	}
+	// a zero rate is accepted
+	if rows3, ok := renegotiate(loan, rows, month, 0, 0.01); !ok || rows3[month].Interest != 0 {
+		t.Errorf("renegotiation at 0%% = %v, interest %.2f, want accepted without interest", ok, rows3[month].Interest)
+	}
+}
@@ -0,0 +1,402 @@
+package immo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Commands for the statistics of the cities.",
+	RunE:  runImmo,
+}
+
+var statsImportDVFCmd = &cobra.Command{
+	Use:   "import-dvf <file>...",
+	Short: "Compute the prices per m² of the cities from DVF files, and refresh the cities section.",
+	Long: `Compute the median and the quartiles of the prices per m² of the houses and the apartments
+from the files of the Demandes de Valeurs Foncières (DVF), downloaded beforehand from
+data.gouv.fr: the geolocated files (geo-dvf, .csv or .csv.gz) or the raw files of the DGFiP
+(ValeursFoncieres-YYYY.txt).
+
+Only the sales of a single house or apartment are kept, possibly with dependencies like a garage.
+The sales of several dwellings or of a dwelling with a shop are mixed, and their price per m²
+is meaningless. The outliers are removed with the fences of Tukey.
+
+By default, the cities of the goods and of the cities section are computed. With --by commune,
+the sales are grouped by commune (INSEE code) rather than by zip code, and all the sales of the
+communes of these zip codes are kept.
+
+With --write, the cities section of immo.yaml is refreshed: the cities are updated or added, the
+comments are kept. The whole file is rewritten, so a copy of the previous file is kept in
+immo.yaml.bak.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runStatsImportDVF,
+}
+
+var (
+	statsImportDVFOutput   string
+	statsImportDVFFrom     string
+	statsImportDVFTo       string
+	statsImportDVFZipCodes []string
+	statsImportDVFBy       string
+	statsImportDVFMinSales int
+	statsImportDVFWrite    bool
+)
+
+// Groupings of the sales.
+const (
+	groupByZipCode = "zip_code"
+	groupByCommune = "commune"
+)
+
+func init() {
+	statsImportDVFCmd.Flags().StringVarP(&statsImportDVFOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	statsImportDVFCmd.Flags().StringVar(&statsImportDVFFrom, "from", "", "Only keep the sales from this date, e.g. 2023-01-01")
+	statsImportDVFCmd.Flags().StringVar(&statsImportDVFTo, "to", "", "Only keep the sales until this date, e.g. 2024-12-31")
+	statsImportDVFCmd.Flags().StringSliceVar(&statsImportDVFZipCodes, "zip-code", nil, "Zip codes to compute (default: the zip codes of the goods and the cities)")
+	statsImportDVFCmd.Flags().StringVar(&statsImportDVFBy, "by", groupByZipCode, "Group the sales by zip_code or by commune")
+	statsImportDVFCmd.Flags().IntVar(&statsImportDVFMinSales, "min-sales", 5, "Minimum number of sales of a type of good to compute its prices")
+	statsImportDVFCmd.Flags().BoolVar(&statsImportDVFWrite, "write", false, "Refresh the cities section of immo.yaml")
+	statsCmd.AddCommand(statsImportDVFCmd)
+}
+
+// DVFImport is the result of the import of DVF files.
+type DVFImport struct {
+	Files        []string      `yaml:"files" json:"files"`
+	From         string        `yaml:"from,omitempty" json:"from,omitempty"`
+	To           string        `yaml:"to,omitempty" json:"to,omitempty"`
+	Transactions int           `yaml:"transactions" json:"transactions"` // sales kept, before removing the outliers
+	Excluded     DVFExclusions `yaml:"excluded" json:"excluded"`
+	Cities       []CityStats   `yaml:"cities" json:"cities"`
+}
+
+func runStatsImportDVF(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(statsImportDVFOutput)
+	if err != nil {
+		return err
+	}
+	if statsImportDVFBy != groupByZipCode && statsImportDVFBy != groupByCommune {
+		return fmt.Errorf("unsupported grouping %q, expected %s or %s", statsImportDVFBy, groupByZipCode, groupByCommune)
+	}
+	from, err := parseDate(statsImportDVFFrom)
+	if err != nil {
+		return fmt.Errorf("invalid --from: %w", err)
+	}
+	to, err := parseDate(statsImportDVFTo)
+	if err != nil {
+		return fmt.Errorf("invalid --to: %w", err)
+	}
+
+	path, data, err := readConfig()
+	if err != nil {
+		return err
+	}
+	var cfg ImmoConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	zipCodes := statsImportDVFZipCodes
+	if len(zipCodes) == 0 {
+		zipCodes = cfg.zipCodes()
+	}
+
+	result := DVFImport{Files: args, From: statsImportDVFFrom, To: statsImportDVFTo}
+	var transactions []dvfTransaction
+	for _, file := range args {
+		fmt.Fprintf(os.Stderr, "Reading %s\n", file)
+		rows, err := readDVF(file)
+		if err != nil {
+			return err
+		}
+		transactions = append(transactions, dvfTransactions(rows, from, to, &result.Excluded)...)
+	}
+	if len(zipCodes) > 0 {
+		transactions = transactionsOf(transactions, zipCodes, statsImportDVFBy)
+	}
+	result.Transactions = len(transactions)
+	result.Cities = cityStatsOf(transactions, statsImportDVFBy, statsImportDVFMinSales, &result.Excluded)
+	if len(result.Cities) == 0 {
+		return errors.New("no city has enough sales, check the files, the period and the zip codes")
+	}
+
+	if statsImportDVFWrite {
+		updated, err := refreshCities(data, result.Cities, statsImportDVFBy)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path+".bak", data, 0o644); err != nil {
+			return fmt.Errorf("failed to back up %s: %w", path, err)
+		}
+		if err := os.WriteFile(path, updated, 0o644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Refreshed %d cities in %s, previous file kept in %s.bak\n", len(result.Cities), path, path)
+	}
+
+	if format.isDocument() {
+		return writeDocument(os.Stdout, format, result)
+	}
+	return writeTable(os.Stdout, format, result.table())
+}
+
+// parseDate parses a date like 2024-12-31. It returns the zero time for an empty string.
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateOnly, s)
+}
+
+// zipCodes returns the zip codes of the goods and of the cities of the configuration.
+func (c ImmoConfig) zipCodes() []string {
+	var zipCodes []string
+	for _, good := range c.Goods {
+		zipCodes = append(zipCodes, good.ZipCode)
+	}
+	for _, city := range c.CityStats {
+		zipCodes = append(zipCodes, city.ZipCode)
+	}
+	slices.Sort(zipCodes)
+	return slices.Compact(slices.DeleteFunc(zipCodes, func(z string) bool { return z == "" }))
+}
+
+// transactionsOf returns the transactions of the zip codes. When grouping by commune, it returns
+// all the transactions of the communes of the zip codes: a commune can span several zip codes.
+func transactionsOf(transactions []dvfTransaction, zipCodes []string, by string) []dvfTransaction {
+	inZipCodes := func(t dvfTransaction) bool { return slices.Contains(zipCodes, t.zipCode) }
+	if by != groupByCommune {
+		return slices.DeleteFunc(transactions, func(t dvfTransaction) bool { return !inZipCodes(t) })
+	}
+	communes := make(map[string]bool)
+	for _, t := range transactions {
+		if inZipCodes(t) {
+			communes[t.commune] = true
+		}
+	}
+	return slices.DeleteFunc(transactions, func(t dvfTransaction) bool { return !communes[t.commune] })
+}
+
+// cityStatsOf computes the prices per m² of each group of sales, by zip code or by commune. The
+// types of goods with less than the minimum number of sales are not computed.
+func cityStatsOf(transactions []dvfTransaction, by string, minSales int, excluded *DVFExclusions) []CityStats {
+	groups := make(map[string][]dvfTransaction)
+	for _, t := range transactions {
+		key := t.zipCode
+		if by == groupByCommune {
+			key = t.commune
+		}
+		groups[key] = append(groups[key], t)
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var cities []CityStats
+	for _, key := range keys {
+		var (
+			group  = groups[key]
+			prices = map[string][]float64{}
+			names  = map[string]int{}
+			zips   = map[string]int{}
+			first  = group[0].date
+			last   = group[0].date
+		)
+		for _, t := range group {
+			prices[t.goodType] = append(prices[t.goodType], t.pricePerM2)
+			names[t.communeName]++
+			zips[t.zipCode]++
+			if t.date.Before(first) {
+				first = t.date
+			}
+			if t.date.After(last) {
+				last = t.date
+			}
+		}
+		city := CityStats{
+			Name:    mostFrequent(names),
+			ZipCode: mostFrequent(zips),
+			Period:  first.Format(time.DateOnly) + "/" + last.Format(time.DateOnly),
+		}
+		if by == groupByCommune {
+			city.Commune = key
+		}
+		var computed bool
+		for goodType, p := range prices {
+			kept := withoutOutliers(p)
+			excluded.Outliers += len(p) - len(kept)
+			if len(kept) < minSales {
+				continue
+			}
+			d := priceDistribution(kept)
+			if goodType == "house" {
+				city.HouseAveragePricePerM2 = d.Median
+				city.HousePricePerM2Q1, city.HousePricePerM2Q3, city.HouseSales = d.Q1, d.Q3, d.Sales
+			} else {
+				city.ApartmentAveragePricePerM2 = d.Median
+				city.ApartmentPricePerM2Q1, city.ApartmentPricePerM2Q3, city.ApartmentSales = d.Q1, d.Q3, d.Sales
+			}
+			computed = true
+		}
+		if computed {
+			cities = append(cities, city)
+		}
+	}
+	return cities
+}
+
+// mostFrequent returns the most frequent value, the first one in alphabetical order in case of a
+// tie.
+func mostFrequent(counts map[string]int) string {
+	var (
+		best      string
+		bestCount int
+	)
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best, bestCount = value, count
+		}
+	}
+	return best
+}
+
+// replaces reports whether the city replaces the other city of its zip code in the evaluation of
+// the goods: the city typed without commune, or else the commune with the most sales.
+func (c CityStats) replaces(other CityStats) bool {
+	if (c.Commune == "") != (other.Commune == "") {
+		return c.Commune == ""
+	}
+	return c.HouseSales+c.ApartmentSales > other.HouseSales+other.ApartmentSales
+}
+
+// refreshCities returns the configuration where the cities are updated, matched by the key of the
+// grouping, or added to the cities section. When grouping by commune, a city without commune is
+// matched by its zip code. The names typed in the configuration are kept, as well as the prices of
+// the types of goods which could not be computed.
+func refreshCities(data []byte, cities []CityStats, by string) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("configuration must be a mapping")
+	}
+	root := doc.Content[0]
+	section := mappingValue(root, "cities")
+	if section == nil {
+		section = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "cities"}, section)
+	}
+	if section.Kind != yaml.SequenceNode {
+		return nil, errors.New("cities must be a list")
+	}
+
+	for _, city := range cities {
+		var computed yaml.Node
+		if err := computed.Encode(city); err != nil {
+			return nil, err
+		}
+		existing := findCity(section, city, by)
+		if existing == nil {
+			section.Content = append(section.Content, &computed)
+			continue
+		}
+		for i := 0; i+1 < len(computed.Content); i += 2 {
+			key, value := computed.Content[i], computed.Content[i+1]
+			// zero prices are the types of goods without enough sales
+			if value.Value == "0" || (key.Value == "name" && mappingValue(existing, "name") != nil) {
+				continue
+			}
+			setMappingValue(existing, key.Value, value)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// findCity returns the node of the city in the cities section, or nil when it is missing.
+func findCity(section *yaml.Node, city CityStats, by string) *yaml.Node {
+	value := func(item *yaml.Node, key string) string {
+		if v := mappingValue(item, key); v != nil {
+			return v.Value
+		}
+		return ""
+	}
+	if by == groupByCommune {
+		for _, item := range section.Content {
+			if value(item, "commune") == city.Commune {
+				return item
+			}
+		}
+	}
+	for _, item := range section.Content {
+		if value(item, "zip_code") == city.ZipCode && (by != groupByCommune || value(item, "commune") == "") {
+			return item
+		}
+	}
+	return nil
+}
+
+// setMappingValue sets the value of a key of a mapping node, keeping the position and the comments
+// of an existing key.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value.HeadComment, value.LineComment = node.Content[i+1].HeadComment, node.Content[i+1].LineComment
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+func (r DVFImport) table() table {
+	t := table{headers: []string{
+		"zip_code",
+		"name",
+		"period",
+		"house_sales",
+		"house_q1",
+		"house_median",
+		"house_q3",
+		"apartment_sales",
+		"apartment_q1",
+		"apartment_median",
+		"apartment_q3",
+	}}
+	for _, c := range r.Cities {
+		t.append(
+			c.ZipCode,
+			c.Name,
+			c.Period,
+			fmt.Sprint(c.HouseSales),
+			formatAmount(c.HousePricePerM2Q1),
+			formatAmount(c.HouseAveragePricePerM2),
+			formatAmount(c.HousePricePerM2Q3),
+			fmt.Sprint(c.ApartmentSales),
+			formatAmount(c.ApartmentPricePerM2Q1),
+			formatAmount(c.ApartmentAveragePricePerM2),
+			formatAmount(c.ApartmentPricePerM2Q3),
+		)
+	}
+	return t
+}
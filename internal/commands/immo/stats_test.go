@@ -0,0 +1,96 @@
+package immo
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestTransactionsOf(t *testing.T) {
+	transactions := []dvfTransaction{
+		{zipCode: "92160", commune: "92002"},
+		{zipCode: "92166", commune: "92002"}, // cedex of the same commune
+		{zipCode: "92330", commune: "92071"},
+		{zipCode: "92330", commune: "92046"},
+	}
+	tests := []struct {
+		by   string
+		want int
+	}{
+		{by: groupByZipCode, want: 1},
+		{by: groupByCommune, want: 2},
+	}
+	for _, tt := range tests {
+		in := append([]dvfTransaction(nil), transactions...)
+		if got := transactionsOf(in, []string{"92160"}, tt.by); len(got) != tt.want {
+			t.Errorf("transactionsOf() by %s = %v, want %d transactions", tt.by, got, tt.want)
+		}
+	}
+}
+
+func TestRefreshCities(t *testing.T) {
+	data := []byte(`cities:
+  # typed by hand
+  - name: Sceaux
+    zip_code: "92330"
+    house_average_price_per_m2: 9000
+    apartment_average_price_per_m2: 8000
+`)
+	cities := []CityStats{
+		{Name: "SCEAUX", ZipCode: "92330", Commune: "92071", HouseAveragePricePerM2: 9500},
+		{Name: "ANTONY", ZipCode: "92330", Commune: "92002", ApartmentAveragePricePerM2: 6000},
+	}
+	updated, err := refreshCities(data, cities, groupByCommune)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(updated), "# typed by hand") {
+		t.Errorf("comment lost:\n%s", updated)
+	}
+	var cfg ImmoConfig
+	if err := yaml.Unmarshal(updated, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	// Sceaux is matched by its zip code then by its commune, Antony shares the zip code but is
+	// another commune
+	want := []CityStats{
+		{Name: "Sceaux", ZipCode: "92330", Commune: "92071", HouseAveragePricePerM2: 9500, ApartmentAveragePricePerM2: 8000},
+		cities[1],
+	}
+	if len(cfg.CityStats) != len(want) {
+		t.Fatalf("cities = %+v, want %+v", cfg.CityStats, want)
+	}
+	for i := range want {
+		if cfg.CityStats[i] != want[i] {
+			t.Errorf("city %d = %+v, want %+v", i, cfg.CityStats[i], want[i])
+		}
+	}
+
+	// the communes sharing a zip code are valid
+	if errs := validateConfig(updated); len(errs) > 0 {
+		t.Errorf("validateConfig() = %v", errs)
+	}
+
+	again, err := refreshCities(updated, cities, groupByCommune)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(updated) {
+		t.Errorf("second refresh changed the configuration:\n%s", again)
+	}
+}
+
+func TestCityStatsReplaces(t *testing.T) {
+	var (
+		typed = CityStats{ZipCode: "92330"}
+		small = CityStats{ZipCode: "92330", Commune: "92071", HouseSales: 10}
+		large = CityStats{ZipCode: "92330", Commune: "92046", HouseSales: 5, ApartmentSales: 20}
+	)
+	if !typed.replaces(large) || large.replaces(typed) {
+		t.Error("the city typed without commune does not win over the communes of its zip code")
+	}
+	if !large.replaces(small) || small.replaces(large) {
+		t.Error("the commune with the most sales does not win")
+	}
+}
@@ -0,0 +1,81 @@
+package immo
+
+import "math"
+
+// Guarantee types of a mortgage.
+const (
+	guaranteeCreditLogement = "credit_logement" // caution of Crédit Logement or a similar organism
+	guaranteeHypotheque     = "hypotheque"      // mortgage on the property
+	guaranteePPD            = "ppd"             // privilège de prêteur de deniers
+	guaranteeNone           = "none"
+)
+
+var guaranteeTypes = []string{guaranteeCreditLogement, guaranteeHypotheque, guaranteePPD, guaranteeNone}
+
+// estimatedGuaranteeRates are the usual costs of the guarantees, as a share of the amount
+// borrowed, used when the cost of the guarantee is not typed. The refundable part of the caution
+// (FMG) is not deducted.
+var estimatedGuaranteeRates = map[string]float64{
+	guaranteeCreditLogement: 0.012,
+	guaranteeHypotheque:     0.02,
+	guaranteePPD:            0.01,
+}
+
+// guaranteeCost returns the cost of the guarantee, or its estimation when it is not typed.
+func (m Mortgage) guaranteeCost() float64 {
+	if m.GuaranteeCost > 0 {
+		return m.GuaranteeCost
+	}
+	return m.Amount * estimatedGuaranteeRates[m.GuaranteeType]
+}
+
+// fees returns the fees paid upfront to get the mortgage: application fees, broker fees and
+// guarantee.
+func (m Mortgage) fees() float64 {
+	return m.ApplicationFees + m.BrokerFees + m.guaranteeCost()
+}
+
+// fees returns the fees paid upfront to get all the loans of the plan.
+func (p FinancingPlan) fees() float64 {
+	var total float64
+	for _, l := range p.Loans {
+		total += l.fees()
+	}
+	return total
+}
+
+// TotalCreditCost returns the total cost of the credit: interest, insurance and fees.
+func (p FinancingPlan) TotalCreditCost() float64 {
+	return p.TotalInterest() + p.TotalInsurance() + p.fees()
+}
+
+// TAEG returns the annual percentage rate of charge (taux annuel effectif global) of the plan: the
+// annual actuarial rate which equals the amount received, net of the upfront fees, with the
+// discounted payments, insurance included. It returns 0 when the terms of the plan are unknown.
+func (p FinancingPlan) TAEG() float64 {
+	if !p.HasTerms() {
+		return 0
+	}
+	var (
+		rows     = p.Schedule()
+		received = p.Amount() - p.fees()
+	)
+	presentValue := func(rate float64) float64 {
+		var total float64
+		for _, row := range rows {
+			total += (row.Payment + row.Insurance) * math.Pow(1+rate, -float64(row.Month)/12)
+		}
+		return total
+	}
+	// the present value decreases with the rate
+	lo, hi := -0.5, 1.0
+	for i := 0; i < bisectionRounds && hi-lo > 1e-9; i++ {
+		mid := (lo + hi) / 2
+		if presentValue(mid) > received {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
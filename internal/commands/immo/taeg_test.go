@@ -0,0 +1,55 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTAEG(t *testing.T) {
+	tests := []struct {
+		name string
+		plan FinancingPlan
+		want float64
+	}{
+		{
+			// without fees nor insurance, the TAEG is the actuarial rate equivalent to the
+			// nominal rate: (1 + 3.5%/12)^12 - 1
+			name: "nominal rate only",
+			plan: singleLoanPlan(Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20}),
+			want: 0.035567,
+		},
+		{
+			name: "fees and insurance",
+			plan: singleLoanPlan(Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20, Insurance: 40, ApplicationFees: 2000}),
+			want: 0.040749,
+		},
+		{
+			name: "estimated guarantee",
+			plan: singleLoanPlan(Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20, GuaranteeType: guaranteeCreditLogement}),
+			want: 0.036978,
+		},
+		{
+			name: "zero rate",
+			plan: singleLoanPlan(Mortgage{Amount: 60000, Years: 20}),
+			want: 0,
+		},
+		{
+			name: "unknown terms",
+			plan: singleLoanPlan(Mortgage{Amount: 200000, MonthlyCost: 1200}),
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.plan.TAEG(); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("%s: TAEG() = %.6f, want %.6f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTotalCreditCost(t *testing.T) {
+	plan := singleLoanPlan(Mortgage{Amount: 200000, InterestRate: 0.035, Years: 20, Insurance: 40, ApplicationFees: 2000})
+	// interest 78380.66 + insurance 40 * 240 + fees 2000
+	if got, want := plan.TotalCreditCost(), 89980.66; math.Abs(got-want) > 0.01 {
+		t.Errorf("TotalCreditCost() = %.2f, want %.2f", got, want)
+	}
+}
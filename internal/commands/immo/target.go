@@ -0,0 +1,223 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var targetPriceCmd = &cobra.Command{
+	Use:   "target-price",
+	Short: "Compute the maximum offer price of each good that fits the family constraints.",
+	RunE:  runTargetPrice,
+}
+
+var (
+	targetPriceOutput             string
+	targetPriceMaxMonthlyExpenses float64
+	targetPriceGood               string
+	targetPriceBank               string
+)
+
+func init() {
+	targetPriceCmd.Flags().StringVarP(&targetPriceOutput, "output", "o", string(outputText), "Output format: text, yaml, json, csv or markdown")
+	targetPriceCmd.Flags().Float64Var(&targetPriceMaxMonthlyExpenses, "max-monthly-expenses", 0, "Maximum monthly expenses after the purchase (default: no ceiling)")
+	targetPriceCmd.Flags().StringVar(&targetPriceGood, "good", "", "Only compute the target price of the good with this name")
+	targetPriceCmd.Flags().StringVar(&targetPriceBank, "bank", "", "Only use the mortgage of this bank, or the financing plan with this name")
+}
+
+// Constraints of the target price.
+const (
+	constraintContribution    = "contribution"
+	constraintMonthlyExpenses = "monthly_expenses"
+	constraintDebtRatio       = "debt_ratio"
+)
+
+// TargetPriceReport is the structured document of the target prices of all scenarios.
+type TargetPriceReport struct {
+	ContributionThreshold float64       `yaml:"contribution_threshold" json:"contribution_threshold"`
+	MaxMonthlyExpenses    float64       `yaml:"max_monthly_expenses,omitempty" json:"max_monthly_expenses,omitempty"`
+	Targets               []TargetPrice `yaml:"targets" json:"targets"`
+}
+
+// TargetPrice is the maximum offer price of a good with a financing plan.
+type TargetPrice struct {
+	Good        string  `yaml:"good" json:"good"`
+	Bank        string  `yaml:"bank" json:"bank"`
+	ListedPrice float64 `yaml:"listed_price" json:"listed_price"`
+
+	// Reachable indicates if a price satisfies the constraints. When it is false, the good does not
+	// work for us even for free, e.g. because the renovation is too expensive.
+	Reachable   bool    `yaml:"reachable" json:"reachable"`
+	TargetPrice float64 `yaml:"target_price" json:"target_price"`
+	Discount    float64 `yaml:"discount" json:"discount"` // required discount on the listed price, e.g. 0.08 for 8%
+
+	// LimitedBy lists the constraints broken just above the target price: contribution,
+	// monthly_expenses or debt_ratio. It is empty when the listed price already works.
+	LimitedBy []string `yaml:"limited_by,omitempty" json:"limited_by,omitempty"`
+
+	Contribution    float64 `yaml:"contribution" json:"contribution"`
+	MonthlyExpenses float64 `yaml:"monthly_expenses" json:"monthly_expenses"`
+	DebtRatio       float64 `yaml:"debt_ratio,omitempty" json:"debt_ratio,omitempty"`
+}
+
+func runTargetPrice(cmd *cobra.Command, args []string) error {
+	format, err := parseOutputFormat(targetPriceOutput)
+	if err != nil {
+		return err
+	}
+	if targetPriceMaxMonthlyExpenses < 0 {
+		return fmt.Errorf("the maximum monthly expenses must be positive, got %g", targetPriceMaxMonthlyExpenses)
+	}
+	cfg, err := loadValidatedConfig()
+	if err != nil {
+		return err
+	}
+
+	report := TargetPriceReport{
+		ContributionThreshold: cfg.Family.ContributionThreshold,
+		MaxMonthlyExpenses:    targetPriceMaxMonthlyExpenses,
+	}
+	for _, s := range scenariosOf(cfg, targetPriceGood, targetPriceBank) {
+		if s.good.Price <= 0 {
+			fmt.Fprintf(os.Stderr, "Skipping good %s: price is missing\n", s.good.Name)
+			continue
+		}
+		report.Targets = append(report.Targets, solveTargetPrice(s, targetPriceMaxMonthlyExpenses))
+	}
+	if len(report.Targets) == 0 {
+		return errors.New("no scenario to solve")
+	}
+
+	if format.isDocument() {
+		return writeDocument(os.Stdout, format, report)
+	}
+	return writeTable(os.Stdout, format, report.table())
+}
+
+// withPrice returns the scenario where the good is bought at the given price. The loans offered
+// are kept, each euro of discount lowers the contribution, unless the loans would finance more
+// than the total cost of the purchase: then they are reduced, the main line first.
+func (s scenario) withPrice(price float64) scenario {
+	s = s.clone()
+	s.good.Price = price
+	var (
+		good   = s.good.withRenovation(planRenovation(s.good, s.cfg.Family.incomeBracket()))
+		cost   = totalPurchaseCost(good, computeAcquisitionFees(good, s.cfg.Fees))
+		excess = s.plan.Amount() - cost
+		main   = s.plan.mainLoanIndex()
+	)
+	reduce := func(loan *Mortgage) {
+		if excess <= 0 || loan.Amount <= 0 {
+			return
+		}
+		reduced := math.Max(loan.Amount-excess, 0)
+		excess -= loan.Amount - reduced
+		loan.MonthlyCost *= reduced / loan.Amount
+		loan.Insurance *= reduced / loan.Amount
+		loan.Amount = reduced
+	}
+	reduce(&s.plan.Loans[main])
+	for i := range s.plan.Loans {
+		if i != main {
+			reduce(&s.plan.Loans[i])
+		}
+	}
+	return s
+}
+
+// brokenConstraints returns the constraints broken by the result of an evaluation.
+func brokenConstraints(result EvaluationResult, threshold, maxMonthlyExpenses float64) []string {
+	var broken []string
+	if result.NewPropertyPurchaseCost.Contribution > threshold {
+		broken = append(broken, constraintContribution)
+	}
+	if maxMonthlyExpenses > 0 && result.NewPropertyOperationalCost.MonthlyExpenses > maxMonthlyExpenses {
+		broken = append(broken, constraintMonthlyExpenses)
+	}
+	if result.DebtRatio != nil && result.DebtRatio.Exceeded() {
+		broken = append(broken, constraintDebtRatio)
+	}
+	return broken
+}
+
+// solveTargetPrice solves the highest price of the good, at most the listed price, such that the
+// contribution stays under the threshold, the monthly expenses stay under the ceiling and the debt
+// ratio stays under the limit. With the loans offered, the contribution grows with the price, and
+// the debt ratio only decreases once the loans are reduced to the cost: all the constraints are
+// monotonic, so the price is solved by bisection.
+func solveTargetPrice(s scenario, maxMonthlyExpenses float64) TargetPrice {
+	var (
+		threshold = s.cfg.Family.ContributionThreshold
+		listed    = s.good.Price
+		target    = TargetPrice{Good: s.good.Name, Bank: s.plan.Name, ListedPrice: math.Round(listed)}
+		broken    = func(price float64) []string {
+			return brokenConstraints(s.withPrice(price).evaluate(), threshold, maxMonthlyExpenses)
+		}
+	)
+
+	price := listed
+	if limitedBy := broken(listed); len(limitedBy) > 0 {
+		if len(broken(1)) > 0 {
+			return target
+		}
+		lo, hi := 1.0, listed
+		for i := 0; i < bisectionRounds && hi-lo > 1; i++ {
+			mid := (lo + hi) / 2
+			if constraints := broken(mid); len(constraints) > 0 {
+				hi, limitedBy = mid, constraints
+			} else {
+				lo = mid
+			}
+		}
+		price = math.Floor(lo)
+		target.LimitedBy = limitedBy
+	}
+
+	result := s.withPrice(price).evaluate()
+	target.Reachable = true
+	target.TargetPrice = price
+	target.Discount = math.Round((listed-price)/listed*10000) / 10000
+	target.Contribution = result.NewPropertyPurchaseCost.Contribution
+	target.MonthlyExpenses = result.NewPropertyOperationalCost.MonthlyExpenses
+	if result.DebtRatio != nil {
+		target.DebtRatio = result.DebtRatio.Ratio
+	}
+	return target
+}
+
+func (r TargetPriceReport) table() table {
+	t := table{headers: []string{
+		"good",
+		"bank",
+		"listed_price",
+		"target_price",
+		"discount",
+		"limited_by",
+		"contribution",
+		"monthly_expenses",
+		"debt_ratio",
+	}}
+	for _, target := range r.Targets {
+		if !target.Reachable {
+			t.append(target.Good, target.Bank, formatAmount(target.ListedPrice), "unreachable", "", "", "", "", "")
+			continue
+		}
+		t.append(
+			target.Good,
+			target.Bank,
+			formatAmount(target.ListedPrice),
+			formatAmount(target.TargetPrice),
+			fmt.Sprintf("%.1f%%", target.Discount*100),
+			strings.Join(target.LimitedBy, ", "),
+			formatAmount(target.Contribution),
+			formatAmount(target.MonthlyExpenses),
+			fmt.Sprintf("%.1f%%", target.DebtRatio*100),
+		)
+	}
+	return t
+}
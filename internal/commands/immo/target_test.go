@@ -0,0 +1,71 @@
+package immo
+
+import (
+	"math"
+	"testing"
+)
+
+func targetScenario(loanAmount float64) scenario {
+	return scenario{
+		cfg: ImmoConfig{
+			Family: FamilyContext{
+				Borrowers:             []Borrower{{Name: "A", MonthlyNetIncome: 6000}, {Name: "B", MonthlyNetIncome: 5000}},
+				MonthlyExpenses:       3000,
+				TotalAssets:           200000,
+				ContributionThreshold: 50000,
+			},
+		},
+		good: Property{
+			Name:                    "house",
+			Price:                   400000,
+			TotalLivingSpaceM2:      100,
+			LivingSpaceLoiCarrezM2:  95,
+			RoomCount:               5,
+			Type:                    "house",
+			ZipCode:                 "92160",
+			EnergyPerformanceRating: "D",
+		},
+		plan: singleLoanPlan(Mortgage{Bank: "bank", Amount: loanAmount, InterestRate: 0.035, Years: 25, Insurance: 60}),
+	}
+}
+
+func TestSolveTargetPrice(t *testing.T) {
+	// the loan of 300000 is kept: the price and its fees must fit in 350000, i.e. p + 5.80665% of
+	// transfer taxes + 0.1% of security contribution + 1200 of debours + the emoluments with VAT,
+	// 1.2 * (1995.25 + 0.799% of (p - 200000)), which gives p = 325945
+	target := solveTargetPrice(targetScenario(300000), 0)
+	if !target.Reachable {
+		t.Fatal("target price unreachable")
+	}
+	if math.Abs(target.TargetPrice-325945) > 1 {
+		t.Errorf("target price = %.0f, want 325945", target.TargetPrice)
+	}
+	if len(target.LimitedBy) != 1 || target.LimitedBy[0] != constraintContribution {
+		t.Errorf("limited by %v, want [%s]", target.LimitedBy, constraintContribution)
+	}
+	if target.Contribution > 50000 {
+		t.Errorf("contribution = %.0f, want at most 50000", target.Contribution)
+	}
+}
+
+func TestWithPrice(t *testing.T) {
+	s := targetScenario(300000)
+
+	// the loan is kept while it is below the cost
+	if got := s.withPrice(350000).plan.Amount(); got != 300000 {
+		t.Errorf("loan at 350000 = %.0f, want 300000", got)
+	}
+	// and reduced to the cost below
+	cheap := s.withPrice(200000)
+	fees := computeAcquisitionFees(cheap.good, cheap.cfg.Fees)
+	if got, want := cheap.plan.Amount(), 200000+fees.Total; math.Abs(got-want) > 0.01 {
+		t.Errorf("loan at 200000 = %.2f, want %.2f", got, want)
+	}
+	if got, want := cheap.plan.Loans[0].Insurance, 60*cheap.plan.Amount()/300000; math.Abs(got-want) > 0.01 {
+		t.Errorf("insurance at 200000 = %.2f, want %.2f", got, want)
+	}
+	// the original scenario is unchanged
+	if s.good.Price != 400000 || s.plan.Amount() != 300000 {
+		t.Errorf("scenario modified: price %.0f, loan %.0f", s.good.Price, s.plan.Amount())
+	}
+}
@@ -6,6 +6,10 @@ type ImmoConfig struct {
 	// EstimatedMortgages is the estimated mortgages for different scenarios.
 	EstimatedMortgages []Mortgage `yaml:"estimated_mortgages"`
 
+	// FinancingPlans are financing scenarios made of several loan lines, evaluated in addition to
+	// the estimated mortgages.
+	FinancingPlans []FinancingPlan `yaml:"financing_plans"`
+
 	// Goods is the list of goods to evaluate.
 	Goods []Property `yaml:"goods"`
 
@@ -14,13 +18,50 @@ type ImmoConfig struct {
 
 	// CurrentProperty is the context of the current property.
 	CurrentProperty CurrentPropertyContext `yaml:"current_property"`
+
+	// Fees configures the computation of the acquisition fees.
+	Fees FeesConfig `yaml:"fees"`
+
+	// Assumptions are the economic hypotheses used by the projections.
+	Assumptions Assumptions `yaml:"assumptions"`
+
+	// Energy configures the estimation of the energy costs.
+	Energy EnergyConfig `yaml:"energy"`
+
+	// Simulation configures the distributions of the simulations.
+	Simulation SimulationConfig `yaml:"simulation"`
+
+	// AlertRules are alerts defined by the user, raised by the evaluation in addition to the
+	// built-in ones.
+	AlertRules []AlertRule `yaml:"alert_rules"`
+
+	// Scoring is the model used to score and rank the goods.
+	Scoring ScoringConfig `yaml:"scoring"`
+
+	// Comparables configures the search of the comparable sales of the goods.
+	Comparables ComparablesConfig `yaml:"comparables"`
+
+	// fairValues are the fair values of the goods keyed by their name, from the sales of the DVF
+	// files of the comparables, loaded on demand.
+	fairValues map[string]*FairValue
 }
 
 type CityStats struct {
-	Name                       string  `yaml:"name"`
-	ZipCode                    string  `yaml:"zip_code"`
-	HouseAveragePricePerM2     float64 `yaml:"house_average_price_per_m2"`
-	ApartmentAveragePricePerM2 float64 `yaml:"apartment_average_price_per_m2"`
+	Name                       string  `yaml:"name" json:"name"`
+	ZipCode                    string  `yaml:"zip_code" json:"zip_code"`
+	HouseAveragePricePerM2     float64 `yaml:"house_average_price_per_m2" json:"house_average_price_per_m2"`
+	ApartmentAveragePricePerM2 float64 `yaml:"apartment_average_price_per_m2" json:"apartment_average_price_per_m2"`
+
+	// The fields below are computed by immo stats import-dvf, the average prices being then the
+	// medians of the sales.
+	HousePricePerM2Q1     float64 `yaml:"house_price_per_m2_q1,omitempty" json:"house_price_per_m2_q1,omitempty"`
+	HousePricePerM2Q3     float64 `yaml:"house_price_per_m2_q3,omitempty" json:"house_price_per_m2_q3,omitempty"`
+	HouseSales            int     `yaml:"house_sales,omitempty" json:"house_sales,omitempty"`
+	ApartmentPricePerM2Q1 float64 `yaml:"apartment_price_per_m2_q1,omitempty" json:"apartment_price_per_m2_q1,omitempty"`
+	ApartmentPricePerM2Q3 float64 `yaml:"apartment_price_per_m2_q3,omitempty" json:"apartment_price_per_m2_q3,omitempty"`
+	ApartmentSales        int     `yaml:"apartment_sales,omitempty" json:"apartment_sales,omitempty"`
+	Period                string  `yaml:"period,omitempty" json:"period,omitempty"`   // first and last dates of the sales, e.g. 2023-01-02/2024-12-30
+	Commune               string  `yaml:"commune,omitempty" json:"commune,omitempty"` // INSEE code, when the sales are grouped by commune
 }
 
 type CurrentPropertyContext struct {
@@ -30,6 +71,31 @@ type CurrentPropertyContext struct {
 	MonthlyCharges    float64 `yaml:"monthly_charges"`
 	GestionFeesRate   float64 `yaml:"gestion_fees_rate"`
 	AnnualPropertyTax float64 `yaml:"annual_property_tax"`
+
+	// EstimatedSalePrice is the estimated price of the current property if we sell it. When it is
+	// set, the evaluation compares keeping and renting the current property with selling it.
+	EstimatedSalePrice float64 `yaml:"estimated_sale_price"`
+
+	// SaleAgencyFeesRate is the rate of the agency fees paid by the seller, e.g. 0.04 for 4%.
+	SaleAgencyFeesRate float64 `yaml:"sale_agency_fees_rate"`
+
+	// RemainingLoanCapital is the capital remaining due on the loan of the current property.
+	RemainingLoanCapital float64 `yaml:"remaining_loan_capital"`
+
+	// LoanInterestRate is the annual rate of the loan of the current property, e.g. 0.015.
+	LoanInterestRate float64 `yaml:"loan_interest_rate"`
+
+	// EnergyPerformanceRating is the DPE letter of the current property, from A to G.
+	EnergyPerformanceRating string `yaml:"energy_performance_rating"`
+
+	// EnergyPerformanceRatingAfterRenovation is the DPE letter expected after a planned renovation.
+	EnergyPerformanceRatingAfterRenovation string `yaml:"energy_performance_rating_after_renovation"`
+
+	// RentalRegime is the tax regime of the rents: micro_foncier (default) or reel.
+	RentalRegime string `yaml:"rental_regime"`
+
+	// BridgeLoan is the bridge loan used to buy the new property before the current one is sold.
+	BridgeLoan *BridgeLoan `yaml:"bridge_loan"`
 }
 
 // FamilyContext represents the family situation. It contains the common information
@@ -59,74 +125,331 @@ type FamilyContext struct {
 
 	// MonthlyElectricityCost is the monthly electricity cost of the family.
 	MonthlyElectricityCost float64 `yaml:"monthly_electricity_cost"`
+
+	// Borrowers are the people borrowing for the purchase, with their incomes.
+	Borrowers []Borrower `yaml:"borrowers"`
+
+	// OtherMonthlyLoanPayments is the total of the monthly payments of the other loans of the family
+	// (car loan, consumer credit, etc.), excluding the mortgage of the current property.
+	OtherMonthlyLoanPayments float64 `yaml:"other_monthly_loan_payments"`
+
+	// DebtRatioLimit is the maximum debt-to-income ratio accepted by banks. Default: 0.35 (HCSF).
+	DebtRatioLimit float64 `yaml:"debt_ratio_limit"`
+
+	// MinimumResteAVivre is the minimum monthly income that must remain after paying the loans.
+	MinimumResteAVivre float64 `yaml:"minimum_reste_a_vivre"`
+
+	// MarginalTaxRate is the marginal income tax rate of the family (TMI), e.g. 0.30 for 30%. It
+	// is used to compute the taxes of the rental incomes.
+	MarginalTaxRate float64 `yaml:"marginal_tax_rate"`
+
+	// IncomeBracket is the income bracket of the household for the renovation subsidies:
+	// very_modest, modest, intermediate or high (default).
+	IncomeBracket string `yaml:"income_bracket"`
 }
 
 // EvaluationContext represents the context of an evaluation.
 type EvaluationContext struct {
 	Family          FamilyContext
 	CurrentProperty CurrentPropertyContext
-	Mortgage        Mortgage
-	CityStats       map[string]CityStats // key: zip code
+	Financing       FinancingPlan
+	CityStats       map[string]CityStats // key: zip code, see CityStats.replaces for the communes sharing one
+	Fees            FeesConfig
+	Energy          EnergyConfig
+	AlertRules      []AlertRule
+	FairValues      map[string]*FairValue // key: good name, no fair value when missing
+
+	// TransferTaxesFactor scales the rate of the transfer taxes to test hypotheses, e.g. 1.1 for
+	// +10%. Zero leaves the rate unchanged.
+	TransferTaxesFactor float64
+}
+
+// EvaluationReport is the structured document of an evaluation, holding the result of every
+// pair of good and financing plan.
+type EvaluationReport struct {
+	Evaluations []Evaluation `yaml:"evaluations" json:"evaluations"`
+}
+
+// Evaluation is the evaluation of a good with a financing plan.
+type Evaluation struct {
+	Good   string           `yaml:"good" json:"good"`
+	Bank   string           `yaml:"bank" json:"bank"` // bank of the mortgage, or name of the financing plan
+	Result EvaluationResult `yaml:"result" json:"result"`
+
+	good      Property
+	financing FinancingPlan
 }
 
 // EvaluationResult represents the result of an evaluation.
 type EvaluationResult struct {
-	CostSummary                CostSummary        `yaml:"cost_summary"`
-	NewPropertyPurchaseCost    PurchaseCost       `yaml:"new_property_purchase"`
-	NewPropertyOperationalCost OperationalCost    `yaml:"new_property_operational_cost"`
-	NewPropertyPerformance     GoodPerformance    `yaml:"new_property_performance"`
-	Renting                    RentingPerformance `yaml:"renting"`
-	Alerts                     []string           `yaml:"alerts"`
+	CostSummary                CostSummary         `yaml:"cost_summary" json:"cost_summary"`
+	NewPropertyPurchaseCost    PurchaseCost        `yaml:"new_property_purchase" json:"new_property_purchase"`
+	NewPropertyOperationalCost OperationalCost     `yaml:"new_property_operational_cost" json:"new_property_operational_cost"`
+	NewPropertyPerformance     GoodPerformance     `yaml:"new_property_performance" json:"new_property_performance"`
+	Renting                    *RentingPerformance `yaml:"renting,omitempty" json:"renting,omitempty"` // nil for a rental investment
+	DebtRatio                  *DebtRatio          `yaml:"debt_ratio,omitempty" json:"debt_ratio,omitempty"`
+	SellVsKeep                 *SellVsKeep         `yaml:"sell_vs_keep,omitempty" json:"sell_vs_keep,omitempty"`
+	BridgeLoan                 *BridgeLoanResult   `yaml:"bridge_loan,omitempty" json:"bridge_loan,omitempty"`
+	Investment                 *InvestmentResult   `yaml:"investment,omitempty" json:"investment,omitempty"`
+	RentalRestrictions         []RentalRestriction `yaml:"rental_restrictions,omitempty" json:"rental_restrictions,omitempty"`
+	Renovation                 *RenovationPlan     `yaml:"renovation,omitempty" json:"renovation,omitempty"`
+	Alerts                     []Alert             `yaml:"alerts" json:"alerts"`
+
+	// financing is the financing plan evaluated, with the eco-PTZ of the renovation when it is
+	// borrowed.
+	financing FinancingPlan
 }
 
 type PurchaseCost struct {
-	TotalPurchaseCost     float64 `yaml:"total_purchase_cost"` // house + fees
-	Contribution          float64 `yaml:"contribution"`
-	MortgageAmount        float64 `yaml:"mortgage_amount"`
-	RemainingAssets       float64 `yaml:"remaining_assets"` // after initial contribution
-	RenovationCost        float64 `yaml:"renovation_cost"`
-	RenovationDescription string  `yaml:"renovation_description"`
-	FournitureCost        float64 `yaml:"fourniture_cost"`
+	TotalPurchaseCost     float64         `yaml:"total_purchase_cost" json:"total_purchase_cost"` // house + fees
+	AcquisitionFees       AcquisitionFees `yaml:"acquisition_fees" json:"acquisition_fees"`
+	MortgageFees          float64         `yaml:"mortgage_fees" json:"mortgage_fees"` // application, broker and guarantee
+	Contribution          float64         `yaml:"contribution" json:"contribution"`
+	MortgageAmount        float64         `yaml:"mortgage_amount" json:"mortgage_amount"`
+	RemainingAssets       float64         `yaml:"remaining_assets" json:"remaining_assets"` // after initial contribution
+	RenovationCost        float64         `yaml:"renovation_cost" json:"renovation_cost"`
+	RenovationDescription string          `yaml:"renovation_description" json:"renovation_description"`
+	FournitureCost        float64         `yaml:"fourniture_cost" json:"fourniture_cost"`
+}
+
+// AcquisitionFees is the detail of the acquisition fees, also known as "frais de notaire".
+type AcquisitionFees struct {
+	TaxableBase          float64 `yaml:"taxable_base" json:"taxable_base"`                   // price without furniture
+	TransferTaxesRate    float64 `yaml:"transfer_taxes_rate" json:"transfer_taxes_rate"`     // DMTO, or taxe de publicité foncière for new builds
+	TransferTaxes        float64 `yaml:"transfer_taxes" json:"transfer_taxes"`               // paid to the state and local authorities
+	NotaryEmoluments     float64 `yaml:"notary_emoluments" json:"notary_emoluments"`         // regulated remuneration of the notary, VAT included
+	SecurityContribution float64 `yaml:"security_contribution" json:"security_contribution"` // contribution de sécurité immobilière
+	Debours              float64 `yaml:"debours" json:"debours"`                             // disbursements and formalities
+	Total                float64 `yaml:"total" json:"total"`
 }
 
 type OperationalCost struct {
-	MonthlyMortgageCost    float64 `yaml:"monthly_mortgage_cost"`
-	MonthlyHousingCharges  float64 `yaml:"monthly_housing_charges"`
-	MonthlyExpenses        float64 `yaml:"monthly_expenses"`
-	MonthlyExpensesDiff    string  `yaml:"monthly_expenses_diff"`
-	AnnualPropertyTax      float64 `yaml:"annual_property_tax"`
-	TotalAnnualHousingCost float64 `yaml:"total_annual_housing_cost"`
+	MonthlyMortgageCost    float64 `yaml:"monthly_mortgage_cost" json:"monthly_mortgage_cost"`
+	MortgageTotalInterest  float64 `yaml:"mortgage_total_interest" json:"mortgage_total_interest"`
+	MortgageTotalInsurance float64 `yaml:"mortgage_total_insurance" json:"mortgage_total_insurance"`
+	TotalCreditCost        float64 `yaml:"total_credit_cost" json:"total_credit_cost"` // interest, insurance and fees
+	TAEG                   float64 `yaml:"taeg" json:"taeg"`
+	MonthlyHousingCharges  float64 `yaml:"monthly_housing_charges" json:"monthly_housing_charges"`
+	MonthlyExpenses        float64 `yaml:"monthly_expenses" json:"monthly_expenses"`
+	MonthlyExpensesDiff    string  `yaml:"monthly_expenses_diff" json:"monthly_expenses_diff"`
+	AnnualPropertyTax      float64 `yaml:"annual_property_tax" json:"annual_property_tax"`
+	TotalAnnualHousingCost float64 `yaml:"total_annual_housing_cost" json:"total_annual_housing_cost"`
+
+	// Energy is the estimation of the energy costs, when the DPE of the good is known.
+	Energy *EnergyEstimate `yaml:"energy,omitempty" json:"energy,omitempty"`
+
+	// FinancingLines is the detail of each loan line, for financing plans with several lines.
+	FinancingLines []FinancingLine `yaml:"financing_lines,omitempty" json:"financing_lines,omitempty"`
+}
+
+// EnergyEstimate is the estimation of the annual energy costs of a good.
+type EnergyEstimate struct {
+	HeatingEnergy      string      `yaml:"heating_energy" json:"heating_energy"`
+	HeatingEnergyAfter string      `yaml:"heating_energy_after,omitempty" json:"heating_energy_after,omitempty"` // when the renovation changes the heating
+	Before             EnergyCost  `yaml:"before" json:"before"`
+	After              *EnergyCost `yaml:"after,omitempty" json:"after,omitempty"` // after the renovation
+}
+
+// EnergyCost is the annual energy cost of a good, split between the heating and the other uses.
+type EnergyCost struct {
+	Source      string  `yaml:"source" json:"source"`           // listing, consumption or dpe_rating
+	Consumption float64 `yaml:"consumption" json:"consumption"` // kWh of primary energy per m² per year
+	AnnualKWh   float64 `yaml:"annual_kwh" json:"annual_kwh"`   // final energy
+	HeatingCost float64 `yaml:"heating_cost" json:"heating_cost"`
+	OtherCost   float64 `yaml:"other_cost" json:"other_cost"` // hot water, lighting, ventilation, cooling
+	AnnualCost  float64 `yaml:"annual_cost" json:"annual_cost"`
+}
+
+// FinancingLine is the summary of a loan line of a financing plan.
+type FinancingLine struct {
+	Bank           string  `yaml:"bank" json:"bank"`
+	Kind           string  `yaml:"kind,omitempty" json:"kind,omitempty"`
+	Amount         float64 `yaml:"amount" json:"amount"`
+	InterestRate   float64 `yaml:"interest_rate" json:"interest_rate"`
+	Years          int     `yaml:"years" json:"years"`
+	DeferredMonths int     `yaml:"deferred_months,omitempty" json:"deferred_months,omitempty"`
+	MonthlyPayment float64 `yaml:"monthly_payment" json:"monthly_payment"` // after the deferral, without insurance
+	TotalInterest  float64 `yaml:"total_interest" json:"total_interest"`
+	TotalInsurance float64 `yaml:"total_insurance" json:"total_insurance"`
+}
+
+// DebtRatio is the debt-to-income ratio (taux d'endettement) of the family after the purchase.
+type DebtRatio struct {
+	MonthlyNetIncome     float64 `yaml:"monthly_net_income" json:"monthly_net_income"`
+	WeightedRentalIncome float64 `yaml:"weighted_rental_income" json:"weighted_rental_income"` // 70% of the rents
+	MonthlyLoanPayments  float64 `yaml:"monthly_loan_payments" json:"monthly_loan_payments"`   // insurance included
+	Ratio                float64 `yaml:"ratio" json:"ratio"`
+	Limit                float64 `yaml:"limit" json:"limit"`
+}
+
+// SellVsKeep compares keeping and renting the current property with selling it.
+type SellVsKeep struct {
+	Sale Sale            `yaml:"sale" json:"sale"`
+	Keep ScenarioSummary `yaml:"keep" json:"keep"`
+	Sell ScenarioSummary `yaml:"sell" json:"sell"`
+}
+
+// Sale is the detail of the sale of the current property.
+type Sale struct {
+	SalePrice             float64 `yaml:"sale_price" json:"sale_price"`
+	AgencyFees            float64 `yaml:"agency_fees" json:"agency_fees"`
+	RemainingLoanCapital  float64 `yaml:"remaining_loan_capital" json:"remaining_loan_capital"`
+	EarlyRepaymentPenalty float64 `yaml:"early_repayment_penalty" json:"early_repayment_penalty"` // IRA
+	NetProceeds           float64 `yaml:"net_proceeds" json:"net_proceeds"`
+}
+
+// ScenarioSummary contains the key metrics of a scenario, to compare it with others.
+type ScenarioSummary struct {
+	Contribution    float64 `yaml:"contribution" json:"contribution"`
+	RemainingAssets float64 `yaml:"remaining_assets" json:"remaining_assets"`
+	MonthlyExpenses float64 `yaml:"monthly_expenses" json:"monthly_expenses"`
+	DebtRatio       float64 `yaml:"debt_ratio,omitempty" json:"debt_ratio,omitempty"`
+}
+
+// BridgeLoanResult is the financing of the purchase with a bridge loan, until the current property
+// is sold.
+type BridgeLoanResult struct {
+	Amount              float64              `yaml:"amount" json:"amount"`
+	Repayment           string               `yaml:"repayment" json:"repayment"`
+	Contribution        float64              `yaml:"contribution" json:"contribution"`                   // at the purchase, before the sale
+	MonthlyBridgeCost   float64              `yaml:"monthly_bridge_cost" json:"monthly_bridge_cost"`     // insurance included
+	MonthlyDoubleBurden float64              `yaml:"monthly_double_burden" json:"monthly_double_burden"` // both mortgages and the bridge loan
+	DebtRatio           float64              `yaml:"debt_ratio,omitempty" json:"debt_ratio,omitempty"`   // until the sale, without rents
+	Scenarios           []BridgeSaleScenario `yaml:"scenarios" json:"scenarios"`
+}
+
+// BridgeSaleScenario is the outcome of the bridge loan when the sale happens at a given month.
+type BridgeSaleScenario struct {
+	SaleDelayMonths      int     `yaml:"sale_delay_months" json:"sale_delay_months"` // compared to the expected sale
+	SaleMonth            int     `yaml:"sale_month" json:"sale_month"`               // after the purchase
+	BridgeInterest       float64 `yaml:"bridge_interest" json:"bridge_interest"`
+	CarryingCost         float64 `yaml:"carrying_cost" json:"carrying_cost"` // current property and bridge loan until the sale
+	RepaymentAtSale      float64 `yaml:"repayment_at_sale" json:"repayment_at_sale"`
+	NetProceedsAfterLoan float64 `yaml:"net_proceeds_after_loan" json:"net_proceeds_after_loan"`
+	ExceedsBridgeTerm    bool    `yaml:"exceeds_bridge_term" json:"exceeds_bridge_term"`
+}
+
+// RenovationPlan is the detail of the itemized renovation of a good, with its subsidies.
+type RenovationPlan struct {
+	IncomeBracket     string           `yaml:"income_bracket" json:"income_bracket"`
+	Items             []RenovationLine `yaml:"items" json:"items"`
+	GrossCost         float64          `yaml:"gross_cost" json:"gross_cost"`
+	MaPrimeRenov      float64          `yaml:"ma_prime_renov" json:"ma_prime_renov"`
+	CEE               float64          `yaml:"cee" json:"cee"`
+	NetCost           float64          `yaml:"net_cost" json:"net_cost"`
+	EcoPTZ            float64          `yaml:"eco_ptz" json:"eco_ptz"` // maximum zero-rate loan for the energy works, borrowed with eco_ptz_years
+	ConsumptionBefore float64          `yaml:"consumption_before,omitempty" json:"consumption_before,omitempty"`
+	ConsumptionAfter  float64          `yaml:"consumption_after,omitempty" json:"consumption_after,omitempty"`
+	RatingAfter       string           `yaml:"rating_after,omitempty" json:"rating_after,omitempty"`
+}
+
+// RenovationLine is an item of a renovation plan, with its subsidies.
+type RenovationLine struct {
+	Name         string  `yaml:"name" json:"name"`
+	Category     string  `yaml:"category" json:"category"`
+	Cost         float64 `yaml:"cost" json:"cost"`
+	MaPrimeRenov float64 `yaml:"ma_prime_renov" json:"ma_prime_renov"`
+	CEE          float64 `yaml:"cee" json:"cee"`
+	NetCost      float64 `yaml:"net_cost" json:"net_cost"`
+}
+
+// RentalRestriction is the restriction of the rental of a home by the Loi Climat et Résilience,
+// based on its DPE letter.
+type RentalRestriction struct {
+	Scenario              string `yaml:"scenario" json:"scenario"` // current_property or investment
+	Rating                string `yaml:"rating" json:"rating"`
+	RatingAfterRenovation string `yaml:"rating_after_renovation,omitempty" json:"rating_after_renovation,omitempty"`
+	BanYear               int    `yaml:"ban_year,omitempty" json:"ban_year,omitempty"` // first year when renting is banned
+	RentFrozen            bool   `yaml:"rent_frozen" json:"rent_frozen"`
+}
+
+// InvestmentResult is the evaluation of a good as a rental investment, over its first year.
+type InvestmentResult struct {
+	AnnualRents     float64     `yaml:"annual_rents" json:"annual_rents"` // after vacancy
+	AnnualExpenses  float64     `yaml:"annual_expenses" json:"annual_expenses"`
+	AnnualLoanCost  float64     `yaml:"annual_loan_cost" json:"annual_loan_cost"` // insurance included
+	GrossYield      float64     `yaml:"gross_yield" json:"gross_yield"`           // rents / price
+	NetYield        float64     `yaml:"net_yield" json:"net_yield"`               // (rents - expenses) / total purchase cost
+	MonthlyCashFlow float64     `yaml:"monthly_cash_flow" json:"monthly_cash_flow"`
+	Regimes         []RentalTax `yaml:"regimes" json:"regimes"`
+	BestRegime      string      `yaml:"best_regime,omitempty" json:"best_regime,omitempty"`
+}
+
+// RentalTax is the taxation of rental incomes under a tax regime.
+type RentalTax struct {
+	Regime          string  `yaml:"regime" json:"regime"`
+	Eligible        bool    `yaml:"eligible" json:"eligible"` // rents below the ceiling of the regime
+	TaxableIncome   float64 `yaml:"taxable_income" json:"taxable_income"`
+	IncomeTax       float64 `yaml:"income_tax" json:"income_tax"` // negative when a deficit reduces the income tax
+	SocialLevies    float64 `yaml:"social_levies" json:"social_levies"`
+	Total           float64 `yaml:"total" json:"total"`
+	MonthlyCashFlow float64 `yaml:"monthly_cash_flow,omitempty" json:"monthly_cash_flow,omitempty"` // after taxes, for a rental investment
 }
 
 type CostSummary struct {
-	AnnualPropertyTax float64 `yaml:"annual_property_tax"`
+	AnnualPropertyTax float64 `yaml:"annual_property_tax" json:"annual_property_tax"`
 }
 
 type RentingPerformance struct {
-	NetMonthlyGain    float64 `yaml:"net_monthly_gain"`
-	MonthlyMortgage   float64 `yaml:"monthly_mortgage"`
-	SurfaceM2         float64 `yaml:"surface_m2"`
-	MonthlyIncome     float64 `yaml:"monthly_income"`
-	MonthlyCharges    float64 `yaml:"monthly_charges"`
-	GestionFeesRate   float64 `yaml:"gestion_fees_rate"`
-	GestionFees       float64 `yaml:"gestion_fees"`
-	AnnualPropertyTax float64 `yaml:"annual_property_tax"`
+	NetMonthlyGain    float64 `yaml:"net_monthly_gain" json:"net_monthly_gain"`
+	MonthlyMortgage   float64 `yaml:"monthly_mortgage" json:"monthly_mortgage"`
+	SurfaceM2         float64 `yaml:"surface_m2" json:"surface_m2"`
+	MonthlyIncome     float64 `yaml:"monthly_income" json:"monthly_income"`
+	MonthlyCharges    float64 `yaml:"monthly_charges" json:"monthly_charges"`
+	GestionFeesRate   float64 `yaml:"gestion_fees_rate" json:"gestion_fees_rate"`
+	GestionFees       float64 `yaml:"gestion_fees" json:"gestion_fees"`
+	AnnualPropertyTax float64 `yaml:"annual_property_tax" json:"annual_property_tax"`
+
+	// Tax is the taxation of the rents, and NetMonthlyGainAfterTax the net gain once it is paid.
+	Tax                    *RentalTax `yaml:"tax,omitempty" json:"tax,omitempty"`
+	NetMonthlyGainAfterTax float64    `yaml:"net_monthly_gain_after_tax" json:"net_monthly_gain_after_tax"`
 }
 
 type GoodPerformance struct {
-	PricePerM2        float64 `yaml:"price_per_m2"`
-	AveragePricePerM2 float64 `yaml:"avg_price_per_m2"`
-	Comment           string  `yaml:"comment"`
+	PricePerM2        float64    `yaml:"price_per_m2" json:"price_per_m2"`
+	AveragePricePerM2 float64    `yaml:"avg_price_per_m2" json:"avg_price_per_m2"`
+	Comment           string     `yaml:"comment" json:"comment"`
+	FairValue         *FairValue `yaml:"fair_value,omitempty" json:"fair_value,omitempty"` // from the comparable sales
 }
 
 type Mortgage struct {
 	Bank         string  `yaml:"bank"`
 	Amount       float64 `yaml:"amount"`
-	InterestRate float64 `yaml:"interest_rate"`
+	InterestRate float64 `yaml:"interest_rate"` // annual nominal rate, e.g. 0.035 for 3.5%
 	Years        int     `yaml:"years"`
-	MonthlyCost  float64 `yaml:"monthly_cost"` // without insurance
-	Insurance    float64 `yaml:"insurance"`    // monthly
+	MonthlyCost  float64 `yaml:"monthly_cost"` // without insurance, computed from the rate when possible
+	Insurance    float64 `yaml:"insurance"`    // monthly, ignored when insurance coverages are set
 	Comment      string  `yaml:"comment"`
+
+	// InsuranceCoverages is the borrower insurance of each person, computed from a rate. When it is
+	// set, it replaces the typed monthly insurance.
+	InsuranceCoverages []InsuranceCoverage `yaml:"insurance_coverages"`
+
+	// Kind is the kind of loan when the mortgage is a line of a financing plan: main, ptz,
+	// action_logement, family or other.
+	Kind string `yaml:"kind"`
+
+	// DeferredMonths is the number of months of deferral (différé d'amortissement), included in
+	// the duration of the mortgage.
+	DeferredMonths int `yaml:"deferred_months"`
+
+	// DeferralType is "partial" when the interest is paid during the deferral (default), or
+	// "total" when it is capitalized.
+	DeferralType string `yaml:"deferral_type"`
+
+	// ApplicationFees are the fees charged by the bank to process the application (frais de
+	// dossier).
+	ApplicationFees float64 `yaml:"application_fees"`
+
+	// BrokerFees are the fees of the broker (courtier), if any.
+	BrokerFees float64 `yaml:"broker_fees"`
+
+	// GuaranteeType is the guarantee of the mortgage: credit_logement, hypotheque, ppd or none.
+	GuaranteeType string `yaml:"guarantee_type"`
+
+	// GuaranteeCost is the cost of the guarantee. When it is missing, it is estimated from the
+	// guarantee type.
+	GuaranteeCost float64 `yaml:"guarantee_cost"`
 }
 
 type Property struct {
@@ -150,6 +473,16 @@ type Property struct {
 	// Price is the price of the good shown in the offer. Required.
 	Price float64 `yaml:"price" json:"price"`
 
+	// NewBuild indicates if the good is a new build (neuf or VEFA), which has reduced transfer
+	// taxes. Optional.
+	NewBuild bool `yaml:"new_build,omitempty" json:"new_build,omitempty"`
+
+	// FurnitureValue is the value of the furniture sold with the good (mobilier), which is
+	// removed from the taxable base of the acquisition fees. Optional.
+	//
+	// This is only used in the configuration file.
+	FurnitureValue float64 `yaml:"furniture_value,omitempty" json:"-"`
+
 	// BathroomCount is the number of bathrooms. Optional.
 	AnnualPropertyTax float64 `yaml:"annual_property_tax,omitempty" json:"annual_property_tax,omitempty"`
 
@@ -161,6 +494,20 @@ type Property struct {
 	EnergyPerformanceRatingAfterRenovation string  `yaml:"energy_performance_rating_after_renovation,omitempty" json:"energy_performance_rating_after_renovation,omitempty"`
 	EnergyConsumptionAfterRenovation       float64 `yaml:"energy_consumption_after_renovation,omitempty" json:"energy_consumption_after_renovation,omitempty"`
 
+	// RenovationItems are the planned works, item by item. When they are set, the renovation cost
+	// is their net cost after subsidies, and the consumption after renovation is deduced from
+	// their savings unless it is typed. Optional.
+	//
+	// This is only used in the configuration file.
+	RenovationItems []RenovationItem `yaml:"renovation_items,omitempty" json:"-"`
+
+	// EcoPTZYears is the duration of the eco-PTZ financing the energy works of the renovation
+	// items, up to 20 years. When it is set, the eco-PTZ is added to every financing plan as a
+	// zero-rate loan line, otherwise it is only shown. Optional.
+	//
+	// This is only used in the configuration file.
+	EcoPTZYears int `yaml:"eco_ptz_years,omitempty" json:"-"`
+
 	// ----------
 	// Property Characteristics
 	// ----------
@@ -227,6 +574,15 @@ type Property struct {
 	// ZipCode is the zip code of the good. Required.
 	ZipCode string `yaml:"zip_code" json:"zip_code"`
 
+	// Commune is the INSEE code of the commune of the good, e.g. 92002, used to find its comparable
+	// sales. Optional: defaults to the commune of most of the sales of the zip code.
+	Commune string `yaml:"commune,omitempty" json:"commune,omitempty"`
+
+	// Latitude and Longitude are the coordinates of the good, used to measure the distance to the
+	// comparable sales. Optional.
+	Latitude  float64 `yaml:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude float64 `yaml:"longitude,omitempty" json:"longitude,omitempty"`
+
 	// DistanceByWalkToRer is the distance to the nearest RER station. Optional.
 	DistanceByWalkToRer string `yaml:"distance_by_walk_to_rer,omitempty" json:"distance_by_walk_to_rer,omitempty"`
 
@@ -268,6 +624,12 @@ type Property struct {
 	// ----------
 	FournitureCost float64 `yaml:"fourniture_cost,omitempty" json:"fourniture_cost,omitempty"`
 
+	// Investment describes the expected rents when the good is evaluated as a rental investment
+	// rather than as our home. Optional.
+	//
+	// This is only used in the configuration file.
+	Investment *RentalInvestment `yaml:"investment,omitempty" json:"-"`
+
 	// ----------
 	// Agency Information
 	// ----------
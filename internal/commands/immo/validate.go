@@ -0,0 +1,647 @@
+package immo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration file immo.yaml.",
+	RunE:  runValidate,
+
+	SilenceUsage: true,
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	path, data, err := readConfig()
+	if err != nil {
+		return err
+	}
+	errs := validateConfig(data)
+	if len(errs) == 0 {
+		fmt.Printf("%s is valid\n", path)
+		return nil
+	}
+	for _, e := range errs {
+		fmt.Printf("%s:%s\n", path, e)
+	}
+	return fmt.Errorf("found %d error(s) in %s", len(errs), path)
+}
+
+// ValidationError is an error found in the configuration file.
+type ValidationError struct {
+	Line    int
+	Column  int
+	Path    string // e.g. goods[1].price
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%d:%d: %s: %s", e.Line, e.Column, e.Path, e.Message)
+}
+
+// validationErrors is the list of errors found in a configuration file.
+type validationErrors []ValidationError
+
+func (errs validationErrors) Error() string {
+	lines := make([]string, len(errs))
+	for i, e := range errs {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// fieldRule checks the value of a field. It returns an error message, or an empty string when the
+// value is valid.
+type fieldRule func(value *yaml.Node) string
+
+// ruleSet is the set of rules of a mapping, keyed by field.
+type ruleSet map[string][]fieldRule
+
+var (
+	dpeLetters  = []string{"A", "B", "C", "D", "E", "F", "G"}
+	zipCodeRe   = regexp.MustCompile(`^[0-9]{5}$`)
+	communeRe   = regexp.MustCompile(`^[0-9][0-9AB][0-9]{3}$`)
+	alertCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+)
+
+var propertyRules = ruleSet{
+	"price":                        {positive},
+	"total_living_space_m2":        {positive},
+	"living_space_loi_carrez_m2":   {positive},
+	"land_surface_m2":              {nonNegative},
+	"room_count":                   {positive},
+	"bedroom_count":                {nonNegative},
+	"wc_count":                     {nonNegative},
+	"annual_property_tax":          {nonNegative},
+	"renovation_cost":              {nonNegative},
+	"fourniture_cost":              {nonNegative},
+	"furniture_value":              {nonNegative},
+	"construction_year":            {between(1000, 2100)},
+	"zip_code":                     {matches(zipCodeRe, "a zip code of 5 digits")},
+	"commune":                      {matches(communeRe, "an INSEE code of commune, e.g. 92002 or 2A004")},
+	"latitude":                     {between(-90, 90)},
+	"longitude":                    {between(-180, 180)},
+	"energy_performance_rating":    {oneOf(dpeLetters...)},
+	"energy_greenhouse_gas_rating": {oneOf(dpeLetters...)},
+	"energy_performance_rating_after_renovation": {oneOf(dpeLetters...)},
+	"energy_consumption":                         {nonNegative},
+	"energy_consumption_after_renovation":        {nonNegative},
+	"energy_consumption_annual_cost":             {nonNegative},
+	"eco_ptz_years":                              {between(1, ecoPTZMaxYears)},
+}
+
+var mortgageRules = ruleSet{
+	"amount":          {positive},
+	"interest_rate":   {between(0, 0.2)},
+	"years":           {between(1, 30)},
+	"monthly_cost":    {nonNegative},
+	"insurance":       {nonNegative},
+	"kind":            {oneOf(loanKinds...)},
+	"deferred_months": {between(0, 300)},
+	"deferral_type":   {oneOf(deferralPartial, deferralTotal)},
+
+	"application_fees": {nonNegative},
+	"broker_fees":      {nonNegative},
+	"guarantee_type":   {oneOf(guaranteeTypes...)},
+	"guarantee_cost":   {nonNegative},
+}
+
+var insuranceCoverageRules = ruleSet{
+	"rate":    {between(0, 0.05)},
+	"quotite": {between(0, 1)},
+	"basis":   {oneOf(insuranceBasisInitial, insuranceBasisRemaining)},
+}
+
+var familyRules = ruleSet{
+	"total_assets":                     {nonNegative},
+	"total_liabilities":                {nonNegative},
+	"contribution_threshold":           {nonNegative},
+	"monthly_expenses":                 {positive},
+	"monthly_housing_charges":          {nonNegative},
+	"home_surface_m2":                  {nonNegative},
+	"monthly_parking_fee":              {nonNegative},
+	"monthly_secondary_residence_cost": {nonNegative},
+	"monthly_electricity_cost":         {nonNegative},
+	"other_monthly_loan_payments":      {nonNegative},
+	"debt_ratio_limit":                 {between(0, 1)},
+	"minimum_reste_a_vivre":            {nonNegative},
+	"marginal_tax_rate":                {between(0, 0.45)},
+	"income_bracket":                   {oneOf(incomeBrackets...)},
+}
+
+var investmentRules = ruleSet{
+	"monthly_rent":         {positive},
+	"vacancy_rate":         {between(0, 1)},
+	"monthly_charges":      {nonNegative},
+	"management_fees_rate": {between(0, 1)},
+	"annual_insurance":     {nonNegative},
+	"land_share":           {between(0, 1)},
+}
+
+var renovationItemRules = ruleSet{
+	"category":           {oneOf(worksCategories...)},
+	"cost":               {nonNegative},
+	"consumption_saving": {nonNegative},
+}
+
+var borrowerRules = ruleSet{
+	"monthly_net_income": {nonNegative},
+}
+
+var currentPropertyRules = ruleSet{
+	"monthly_mortgage":    {nonNegative},
+	"surface_m2":          {positive},
+	"monthly_income":      {nonNegative},
+	"monthly_charges":     {nonNegative},
+	"gestion_fees_rate":   {between(0, 1)},
+	"annual_property_tax": {nonNegative},
+
+	"estimated_sale_price":   {nonNegative},
+	"sale_agency_fees_rate":  {between(0, 0.2)},
+	"remaining_loan_capital": {nonNegative},
+	"loan_interest_rate":     {between(0, 0.2)},
+	"rental_regime":          {oneOf(regimeMicroFoncier, regimeReel)},
+
+	"energy_performance_rating":                  {oneOf(dpeLetters...)},
+	"energy_performance_rating_after_renovation": {oneOf(dpeLetters...)},
+}
+
+var bridgeLoanRules = ruleSet{
+	"value_rate":           {between(0, 1)},
+	"interest_rate":        {between(0, 0.2)},
+	"months":               {between(1, 36)},
+	"repayment":            {oneOf(bridgeInterestOnly, bridgeDeferred)},
+	"expected_sale_months": {between(0, 36)},
+	"insurance":            {nonNegative},
+}
+
+var energyRules = ruleSet{
+	"heating_share": {between(0, 1)},
+}
+
+var cityRules = ruleSet{
+	"zip_code":                       {matches(zipCodeRe, "a zip code of 5 digits")},
+	"commune":                        {matches(communeRe, "an INSEE code of commune, e.g. 92002 or 2A004")},
+	"house_average_price_per_m2":     {nonNegative},
+	"apartment_average_price_per_m2": {nonNegative},
+	"house_price_per_m2_q1":          {nonNegative},
+	"house_price_per_m2_q3":          {nonNegative},
+	"house_sales":                    {nonNegative},
+	"apartment_price_per_m2_q1":      {nonNegative},
+	"apartment_price_per_m2_q3":      {nonNegative},
+	"apartment_sales":                {nonNegative},
+}
+
+var feesRules = ruleSet{
+	"default_departmental_rate": {between(0, 0.1)},
+	"debours":                   {nonNegative},
+}
+
+var assumptionsRules = ruleSet{
+	"appreciation_rate":  {between(-0.5, 0.5)},
+	"inflation_rate":     {between(-0.5, 0.5)},
+	"rent_increase_rate": {between(-0.5, 0.5)},
+	"income_growth_rate": {between(-0.5, 0.5)},
+	"asset_return_rate":  {between(-0.5, 0.5)},
+}
+
+var alertRuleRules = ruleSet{
+	"code":     {matches(alertCodeRe, "a code in snake_case, e.g. low_remaining_assets")},
+	"severity": {oneOf(severities...)},
+}
+
+var scoringCriterionRules = ruleSet{
+	"weight":   {positive},
+	"function": {oneOf(scoringFunctions...)},
+}
+
+var simulationRules = ruleSet{
+	"appreciation_stddev":       {between(0, 0.5)},
+	"inflation_stddev":          {between(0, 0.5)},
+	"renegotiation_year":        {between(1, 30)},
+	"rate_change_stddev":        {between(0, 0.1)},
+	"renegotiation_threshold":   {between(0, 0.1)},
+	"renegotiation_fees_rate":   {between(0, 0.1)},
+	"renovation_overrun_mean":   {between(0, 2)},
+	"renovation_overrun_stddev": {between(0, 2)},
+	"vacancy_mean":              {between(0, 1)},
+	"vacancy_stddev":            {between(0, 1)},
+}
+
+var comparablesRules = ruleSet{
+	"months":            {between(1, 120)},
+	"surface_tolerance": {between(0, 1)},
+	"room_tolerance":    {between(0, 10)},
+	"min_sales":         {positive},
+}
+
+// propertySchema is the JSON schema of a property, used to check the required fields and the
+// enum values, so that the validation always agrees with the schema shown by show-schema.
+var propertySchema = (&jsonschema.Reflector{DoNotReference: true}).Reflect(&Property{})
+
+// validateConfig validates the content of the configuration file. It returns all the errors found,
+// with their line and column numbers.
+func validateConfig(data []byte) validationErrors {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return validationErrors{yamlError(err)}
+	}
+	if len(doc.Content) == 0 {
+		return validationErrors{{Line: 1, Column: 1, Message: "configuration is empty"}}
+	}
+
+	// type errors, e.g. a string instead of a number
+	var (
+		cfg        ImmoConfig
+		typeErrors validationErrors
+	)
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return validationErrors{yamlError(err)}
+		}
+		for _, msg := range typeErr.Errors {
+			typeErrors = append(typeErrors, yamlError(errors.New(msg)))
+		}
+	}
+
+	var v validator
+	v.checkConfig(doc.Content[0])
+	v.merge(typeErrors)
+	return v.errs
+}
+
+type validator struct {
+	errs validationErrors
+}
+
+func (v *validator) checkConfig(root *yaml.Node) {
+	if root.Kind != yaml.MappingNode {
+		v.add(root, "", "configuration must be a mapping")
+		return
+	}
+
+	if family := mappingValue(root, "family"); family != nil {
+		v.checkMapping(family, "family", familyRules)
+		if borrowers := mappingValue(family, "borrowers"); borrowers != nil {
+			v.checkSequence(borrowers, "family.borrowers", func(item *yaml.Node, path string) {
+				v.checkMapping(item, path, borrowerRules)
+			})
+		}
+	}
+	if cp := mappingValue(root, "current_property"); cp != nil {
+		v.checkMapping(cp, "current_property", currentPropertyRules)
+		v.checkRequired(cp, "current_property", []string{"surface_m2"})
+		if bridge := mappingValue(cp, "bridge_loan"); bridge != nil {
+			v.checkMapping(bridge, "current_property.bridge_loan", bridgeLoanRules)
+			v.checkRequired(bridge, "current_property.bridge_loan", []string{"interest_rate", "months", "expected_sale_months"})
+			if mappingValue(cp, "estimated_sale_price") == nil {
+				v.add(bridge, "current_property.bridge_loan", "requires current_property.estimated_sale_price")
+			}
+		}
+	}
+	if fees := mappingValue(root, "fees"); fees != nil {
+		v.checkMapping(fees, "fees", feesRules)
+		if rates := mappingValue(fees, "departmental_rates"); rates != nil && rates.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(rates.Content); i += 2 {
+				v.checkValue(rates.Content[i+1], "fees.departmental_rates."+rates.Content[i].Value, between(0, 0.1))
+			}
+		}
+	}
+	if energy := mappingValue(root, "energy"); energy != nil {
+		v.checkMapping(energy, "energy", energyRules)
+		if tariffs := mappingValue(energy, "tariffs"); tariffs != nil && tariffs.Kind == yaml.MappingNode {
+			for i := 0; i+1 < len(tariffs.Content); i += 2 {
+				key := tariffs.Content[i]
+				if _, exists := defaultEnergyTariffs[key.Value]; !exists {
+					v.add(key, "energy.tariffs."+key.Value, "unknown energy, expected one of %s", strings.Join(energyNames(), ", "))
+					continue
+				}
+				v.checkValue(tariffs.Content[i+1], "energy.tariffs."+key.Value, between(0, 2))
+			}
+		}
+	}
+	if assumptions := mappingValue(root, "assumptions"); assumptions != nil {
+		v.checkMapping(assumptions, "assumptions", assumptionsRules)
+	}
+	if simulation := mappingValue(root, "simulation"); simulation != nil {
+		v.checkMapping(simulation, "simulation", simulationRules)
+	}
+	if comparables := mappingValue(root, "comparables"); comparables != nil {
+		v.checkMapping(comparables, "comparables", comparablesRules)
+	}
+	if mortgages := mappingValue(root, "estimated_mortgages"); mortgages != nil {
+		v.checkSequence(mortgages, "estimated_mortgages", func(item *yaml.Node, path string) {
+			v.checkMortgage(item, path)
+			v.checkRequired(item, path, []string{"bank", "amount"})
+		})
+	}
+	if plans := mappingValue(root, "financing_plans"); plans != nil {
+		names := make(map[string]*yaml.Node)
+		v.checkSequence(plans, "financing_plans", func(item *yaml.Node, path string) {
+			v.checkRequired(item, path, []string{"name", "loans"})
+			v.checkUnique(item, path, "name", names)
+			if loans := mappingValue(item, "loans"); loans != nil {
+				v.checkSequence(loans, path+".loans", func(loan *yaml.Node, path string) {
+					v.checkMortgage(loan, path)
+					v.checkRequired(loan, path, []string{"amount"})
+				})
+			}
+		})
+	}
+	if goods := mappingValue(root, "goods"); goods != nil {
+		names := make(map[string]*yaml.Node)
+		v.checkSequence(goods, "goods", func(item *yaml.Node, path string) {
+			v.checkGood(item, path)
+			v.checkUnique(item, path, "name", names)
+		})
+	}
+	if cities := mappingValue(root, "cities"); cities != nil {
+		var (
+			zipCodes = make(map[string]*yaml.Node)
+			communes = make(map[string]*yaml.Node)
+		)
+		v.checkSequence(cities, "cities", func(item *yaml.Node, path string) {
+			v.checkMapping(item, path, cityRules)
+			v.checkRequired(item, path, []string{"zip_code"})
+			// the communes sharing a zip code are told apart by their code
+			if mappingValue(item, "commune") != nil {
+				v.checkUnique(item, path, "commune", communes)
+			} else {
+				v.checkUnique(item, path, "zip_code", zipCodes)
+			}
+		})
+	}
+	if scoring := mappingValue(root, "scoring"); scoring != nil {
+		if criteria := mappingValue(scoring, "criteria"); criteria != nil {
+			v.checkSequence(criteria, "scoring.criteria", v.checkScoringCriterion)
+		}
+	}
+	if rules := mappingValue(root, "alert_rules"); rules != nil {
+		codes := make(map[string]*yaml.Node)
+		v.checkSequence(rules, "alert_rules", func(item *yaml.Node, path string) {
+			v.checkAlertRule(item, path)
+			v.checkUnique(item, path, "code", codes)
+		})
+	}
+}
+
+// checkScoringCriterion checks the fields of a scoring criterion, and parses its field.
+func (v *validator) checkScoringCriterion(node *yaml.Node, path string) {
+	v.checkMapping(node, path, scoringCriterionRules)
+	v.checkRequired(node, path, []string{"field", "weight"})
+	if field := mappingValue(node, "field"); field != nil && field.Kind == yaml.ScalarNode {
+		v.checkExpression(field, path+".field")
+	}
+	if levels := mappingValue(node, "levels"); levels != nil {
+		if levels.Kind != yaml.MappingNode {
+			v.add(levels, path+".levels", "must be a mapping")
+			return
+		}
+		for i := 0; i+1 < len(levels.Content); i += 2 {
+			v.checkValue(levels.Content[i+1], path+".levels."+levels.Content[i].Value, between(0, 1))
+		}
+	}
+}
+
+// checkExpression parses an expression, and checks that its paths start with good or result.
+func (v *validator) checkExpression(node *yaml.Node, path string) {
+	e, err := parseExpr(node.Value)
+	if err != nil {
+		v.add(node, path, "%v", err)
+		return
+	}
+	for _, msg := range checkPathRoots(exprPaths(e)) {
+		v.add(node, path, "%s", msg)
+	}
+}
+
+// checkAlertRule checks the fields of an alert rule, and parses its condition and its message so
+// that a broken rule is reported before any evaluation.
+func (v *validator) checkAlertRule(node *yaml.Node, path string) {
+	v.checkMapping(node, path, alertRuleRules)
+	v.checkRequired(node, path, []string{"code", "when"})
+	if when := mappingValue(node, "when"); when != nil && when.Kind == yaml.ScalarNode {
+		v.checkExpression(when, path+".when")
+	}
+	if message := mappingValue(node, "message"); message != nil && message.Kind == yaml.ScalarNode {
+		for _, msg := range checkPathRoots(messagePaths(message.Value)) {
+			v.add(message, path+".message", "%s", msg)
+		}
+	}
+}
+
+func (v *validator) checkMortgage(node *yaml.Node, path string) {
+	v.checkMapping(node, path, mortgageRules)
+	if coverages := mappingValue(node, "insurance_coverages"); coverages != nil {
+		v.checkSequence(coverages, path+".insurance_coverages", func(item *yaml.Node, path string) {
+			v.checkMapping(item, path, insuranceCoverageRules)
+			v.checkRequired(item, path, []string{"rate", "quotite"})
+		})
+	}
+}
+
+// merge adds the errors of the YAML decoder which are not already reported by the validator, and
+// sorts all the errors by position.
+func (v *validator) merge(errs validationErrors) {
+	lines := make(map[int]bool)
+	for _, e := range v.errs {
+		lines[e.Line] = true
+	}
+	for _, e := range errs {
+		if !lines[e.Line] {
+			v.errs = append(v.errs, e)
+		}
+	}
+	sort.SliceStable(v.errs, func(i, j int) bool {
+		if v.errs[i].Line != v.errs[j].Line {
+			return v.errs[i].Line < v.errs[j].Line
+		}
+		return v.errs[i].Column < v.errs[j].Column
+	})
+}
+
+func (v *validator) add(node *yaml.Node, path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{
+		Line:    node.Line,
+		Column:  node.Column,
+		Path:    path,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *validator) checkSequence(node *yaml.Node, path string, check func(item *yaml.Node, path string)) {
+	if node.Kind != yaml.SequenceNode {
+		v.add(node, path, "must be a list")
+		return
+	}
+	for i, item := range node.Content {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if item.Kind != yaml.MappingNode {
+			v.add(item, itemPath, "must be a mapping")
+			continue
+		}
+		check(item, itemPath)
+	}
+}
+
+func (v *validator) checkMapping(node *yaml.Node, path string, rules ruleSet) {
+	if node.Kind != yaml.MappingNode {
+		v.add(node, path, "must be a mapping")
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		for _, rule := range rules[key.Value] {
+			v.checkValue(value, path+"."+key.Value, rule)
+		}
+	}
+}
+
+func (v *validator) checkValue(value *yaml.Node, path string, rule fieldRule) {
+	if value.Kind != yaml.ScalarNode || value.Tag == "!!null" {
+		return
+	}
+	if msg := rule(value); msg != "" {
+		v.add(value, path, "%s", msg)
+	}
+}
+
+func (v *validator) checkRequired(node *yaml.Node, path string, fields []string) {
+	for _, field := range fields {
+		value := mappingValue(node, field)
+		if value == nil || value.Tag == "!!null" {
+			v.add(node, path, "missing required field %q", field)
+		}
+	}
+}
+
+func (v *validator) checkUnique(node *yaml.Node, path, field string, seen map[string]*yaml.Node) {
+	value := mappingValue(node, field)
+	if value == nil || value.Value == "" {
+		return
+	}
+	if previous, exists := seen[value.Value]; exists {
+		v.add(value, path+"."+field, "duplicate %s %q, already defined at line %d", field, value.Value, previous.Line)
+		return
+	}
+	seen[value.Value] = value
+}
+
+func (v *validator) checkGood(node *yaml.Node, path string) {
+	v.checkRequired(node, path, propertySchema.Required)
+	v.checkMapping(node, path, propertyRules)
+
+	// enum values declared in the schema
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		prop, exists := propertySchema.Properties.Get(key.Value)
+		if !exists || len(prop.Enum) == 0 {
+			continue
+		}
+		values := make([]string, len(prop.Enum))
+		for j, e := range prop.Enum {
+			values[j] = fmt.Sprint(e)
+		}
+		v.checkValue(value, path+"."+key.Value, oneOf(values...))
+	}
+
+	if items := mappingValue(node, "renovation_items"); items != nil {
+		v.checkSequence(items, path+".renovation_items", func(item *yaml.Node, path string) {
+			v.checkMapping(item, path, renovationItemRules)
+			v.checkRequired(item, path, []string{"name", "category", "cost"})
+		})
+	}
+	if investment := mappingValue(node, "investment"); investment != nil {
+		v.checkMapping(investment, path+".investment", investmentRules)
+		v.checkRequired(investment, path+".investment", []string{"monthly_rent"})
+	}
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil if it does not exist.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+var yamlLineRe = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// yamlError converts an error of the YAML parser into a validation error.
+func yamlError(err error) ValidationError {
+	if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+		line, _ := strconv.Atoi(m[1])
+		return ValidationError{Line: line, Column: 1, Message: m[2]}
+	}
+	return ValidationError{Line: 1, Column: 1, Message: err.Error()}
+}
+
+func number(value *yaml.Node) (float64, bool) {
+	if value.Tag != "!!int" && value.Tag != "!!float" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.ReplaceAll(value.Value, "_", ""), 64)
+	return f, err == nil
+}
+
+func positive(value *yaml.Node) string {
+	if f, ok := number(value); !ok || f <= 0 {
+		return fmt.Sprintf("must be a positive number, got %q", value.Value)
+	}
+	return ""
+}
+
+func nonNegative(value *yaml.Node) string {
+	if f, ok := number(value); !ok || f < 0 {
+		return fmt.Sprintf("must be a non-negative number, got %q", value.Value)
+	}
+	return ""
+}
+
+func between(lo, hi float64) fieldRule {
+	return func(value *yaml.Node) string {
+		if f, ok := number(value); !ok || f < lo || f > hi {
+			return fmt.Sprintf("must be a number between %g and %g, got %q", lo, hi, value.Value)
+		}
+		return ""
+	}
+}
+
+func oneOf(values ...string) fieldRule {
+	return func(value *yaml.Node) string {
+		for _, v := range values {
+			if value.Value == v {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s, got %q", strings.Join(values, ", "), value.Value)
+	}
+}
+
+func matches(re *regexp.Regexp, description string) fieldRule {
+	return func(value *yaml.Node) string {
+		if !re.MatchString(value.Value) {
+			return fmt.Sprintf("must be %s, got %q", description, value.Value)
+		}
+		return ""
+	}
+}
@@ -0,0 +1,164 @@
+package immo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// goodYAML returns a valid good with all the required fields, indented as an item of goods. The
+// overrides replace the values of the fields, an empty value removes the field.
+func goodYAML(name string, overrides map[string]string) string {
+	fields := [][2]string{
+		{"name", name},
+		{"offer_url", "https://example.com/" + name},
+		{"offer_description", "a good"},
+		{"price", "400000"},
+		{"total_living_space_m2", "100"},
+		{"living_space_loi_carrez_m2", "95"},
+		{"land_surface_m2", "300"},
+		{"room_count", "5"},
+		{"bedroom_count", "3"},
+		{"type", "house"},
+		{"zip_code", `"92160"`},
+		{"heating_system", "gas"},
+		{"heating_type", "individual"},
+		{"heating_method", "radiators"},
+		{"energy_performance_rating", "D"},
+		{"energy_greenhouse_gas_rating", "E"},
+	}
+	var sb strings.Builder
+	for i, f := range fields {
+		value, overridden := overrides[f[0]]
+		if !overridden {
+			value = f[1]
+		} else if value == "" {
+			continue
+		}
+		prefix := "    "
+		if i == 0 {
+			prefix = "  - "
+		}
+		fmt.Fprintf(&sb, "%s%s: %s\n", prefix, f[0], value)
+	}
+	return sb.String()
+}
+
+func TestValidateConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{
+			name:   "valid",
+			config: "goods:\n" + goodYAML("maison-a", nil) + goodYAML("appart-b", map[string]string{"type": "apartment"}),
+		},
+		{
+			name:   "required fields of the schema",
+			config: "goods:\n" + goodYAML("maison-a", map[string]string{"price": "", "zip_code": ""}),
+			want: []string{
+				`2:5: goods[0]: missing required field "price"`,
+				`2:5: goods[0]: missing required field "zip_code"`,
+			},
+		},
+		{
+			name:   "enums",
+			config: "goods:\n" + goodYAML("maison-a", map[string]string{"type": "castle", "energy_performance_rating": "H"}),
+			want: []string{
+				`11:11: goods[0].type: must be one of house, apartment, got "castle"`,
+				`16:32: goods[0].energy_performance_rating: must be one of A, B, C, D, E, F, G, got "H"`,
+			},
+		},
+		{
+			name: "ranges",
+			config: "goods:\n" + goodYAML("maison-a", map[string]string{"price": "-1", "zip_code": "9216"}) +
+				"estimated_mortgages:\n  - bank: BNP\n    amount: 300000\n    interest_rate: 0.5\n    years: 40\n",
+			want: []string{
+				`5:12: goods[0].price: must be a positive number, got "-1"`,
+				`12:15: goods[0].zip_code: must be a zip code of 5 digits, got "9216"`,
+				`21:20: estimated_mortgages[0].interest_rate: must be a number between 0 and 0.2, got "0.5"`,
+				`22:12: estimated_mortgages[0].years: must be a number between 1 and 30, got "40"`,
+			},
+		},
+		{
+			name: "insurance coverages",
+			config: "estimated_mortgages:\n  - bank: BNP\n    amount: 300000\n    insurance_coverages:\n" +
+				"      - borrower: A\n        rate: 0.003\n        quotite: 1.5\n        basis: current\n" +
+				"      - borrower: B\n        quotite: 0.5\n",
+			want: []string{
+				`7:18: estimated_mortgages[0].insurance_coverages[0].quotite: must be a number between 0 and 1, got "1.5"`,
+				`8:16: estimated_mortgages[0].insurance_coverages[0].basis: must be one of initial, remaining, got "current"`,
+				`9:9: estimated_mortgages[0].insurance_coverages[1]: missing required field "rate"`,
+			},
+		},
+		{
+			name: "duplicates",
+			config: "goods:\n" + goodYAML("maison-a", nil) + goodYAML("maison-a", nil) +
+				"cities:\n  - zip_code: \"92160\"\n  - zip_code: \"92160\"\n",
+			want: []string{
+				`18:11: goods[1].name: duplicate name "maison-a", already defined at line 2`,
+				`36:15: cities[1].zip_code: duplicate zip_code "92160", already defined at line 35`,
+			},
+		},
+		{
+			name: "duplicate financing plans",
+			config: "financing_plans:\n" +
+				"  - name: ptz\n    loans:\n      - amount: 200000\n" +
+				"  - name: ptz\n    loans:\n      - amount: 100000\n",
+			want: []string{`5:11: financing_plans[1].name: duplicate name "ptz", already defined at line 2`},
+		},
+		{
+			// the communes sharing a zip code are told apart by their code
+			name: "duplicate communes",
+			config: "cities:\n" +
+				"  - zip_code: \"78000\"\n    commune: \"78646\"\n" +
+				"  - zip_code: \"78000\"\n    commune: \"78297\"\n" +
+				"  - zip_code: \"78000\"\n    commune: \"78646\"\n" +
+				"  - zip_code: \"92160\"\n    commune: \"2C004\"\n",
+			want: []string{
+				`7:14: cities[2].commune: duplicate commune "78646", already defined at line 3`,
+				`9:14: cities[3].commune: must be an INSEE code of commune, e.g. 92002 or 2A004, got "2C004"`,
+			},
+		},
+		{
+			name: "required fields of the sections",
+			config: "current_property:\n  monthly_mortgage: 800\n" +
+				"estimated_mortgages:\n  - amount: 300000\n",
+			want: []string{
+				`2:3: current_property: missing required field "surface_m2"`,
+				`4:5: estimated_mortgages[0]: missing required field "bank"`,
+			},
+		},
+		{
+			// the rule and the decoder both reject the price: it is reported once, while the type
+			// error of the boolean is only found by the decoder
+			name:   "type errors merged",
+			config: "goods:\n" + goodYAML("maison-a", map[string]string{"price": "cheap"}) + "    has_garden: maybe\n",
+			want: []string{
+				`5:12: goods[0].price: must be a positive number, got "cheap"`,
+				"18:1: cannot unmarshal !!str `maybe` into bool",
+			},
+		},
+		{
+			name:   "syntax error",
+			config: "family:\n  total_assets: 100000\ngoods:\n\t- name: maison-a\n",
+			want:   []string{"4:1: found character that cannot start any token"},
+		},
+		{
+			name:   "not a mapping",
+			config: "- maison-a\n",
+			want:   []string{"1:1: configuration must be a mapping"},
+		},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, e := range validateConfig([]byte(tt.config)) {
+			got = append(got, e.Error())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: validateConfig() =\n%s\nwant\n%s", tt.name, strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+		}
+	}
+}